@@ -0,0 +1,121 @@
+// Package acyclicdig adapts components between acyclicloader and
+// go.uber.org/dig, such that a mixed graph can be used while migrating in
+// either direction.
+//
+// Components from a loader are exposed to a dig container as constructors
+// loading them from the loader:
+//
+//   providers, err := acyclicdig.Providers(loader, "Database", "Logger")
+//   for _, provider := range providers {
+//       container.Provide(provider)
+//   }
+//
+// And dig constructors with plain parameters can be used as components, which
+// are wired by type:
+//
+//   components, err := acyclicdig.Components(NewDatabase, NewUserModel)
+//
+// This package doesn't import dig, so constructors using dig.In, dig.Out or
+// named values are not supported.
+package acyclicdig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+var typeOfError = reflect.TypeOf((*error)(nil)).Elem()
+
+// Providers returns a constructor for each of the named components, or every
+// component of loader if no names are given, for passing to dig's Provide().
+// Each constructor has the form func() (ComponentType, error) and loads the
+// component from loader, so the value is shared with the loader.
+//
+// As dig provides values by type, an error is returned if two of the named
+// components have the same type. When no names are given, components sharing
+// a type with another component and components without a result are skipped.
+func Providers(loader *acyclicloader.AcyclicLoader, names ...string) ([]interface{}, error) {
+	defined := map[string]bool{}
+	for _, name := range loader.ComponentNames() {
+		defined[name] = true
+	}
+	explicit := len(names) > 0
+	if !explicit {
+		names = loader.ComponentNames()
+	}
+
+	byType := map[reflect.Type][]string{}
+	for _, name := range names {
+		if !defined[name] {
+			return nil, fmt.Errorf("cannot provide undefined component '%s' to dig", name)
+		}
+		t := loader.ComponentType(name)
+		if t == nil && explicit {
+			return nil, fmt.Errorf("cannot provide component '%s' to dig, as it doesn't have a result", name)
+		}
+		if t != nil {
+			byType[t] = append(byType[t], name)
+		}
+	}
+
+	var providers []interface{}
+	for _, name := range names {
+		t := loader.ComponentType(name)
+		if t == nil {
+			continue
+		}
+		if shared := byType[t]; len(shared) > 1 {
+			if !explicit {
+				continue
+			}
+			return nil, fmt.Errorf(
+				"cannot provide both '%s' and '%s' to dig, as they have the same type %s",
+				shared[0], shared[1], t.String(),
+			)
+		}
+		providers = append(providers, provider(loader, name, t))
+	}
+	return providers, nil
+}
+
+// provider returns a function of type func() (t, error) loading the named
+// component from loader.
+func provider(loader *acyclicloader.AcyclicLoader, name string, t reflect.Type) interface{} {
+	fnType := reflect.FuncOf(nil, []reflect.Type{t, typeOfError}, false)
+	return reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+		value, err := loader.Load(name)
+		result := reflect.Zero(t)
+		if value != nil {
+			result = reflect.ValueOf(value)
+		}
+		errValue := reflect.Zero(typeOfError)
+		if err != nil {
+			errValue = reflect.ValueOf(&err).Elem()
+		}
+		return []reflect.Value{result, errValue}
+	}).Interface()
+}
+
+// Components returns a set of components defined by dig constructors, each
+// named after the type it returns, such as "*sql.DB". The parameters of the
+// constructors are wired by type, see acyclicloader.Components.
+//
+// An error is returned, if a constructor isn't a function with a result, or if
+// two constructors return the same type.
+func Components(constructors ...interface{}) (acyclicloader.Components, error) {
+	components := make(acyclicloader.Components, len(constructors))
+	for _, constructor := range constructors {
+		t := reflect.TypeOf(constructor)
+		if t == nil || t.Kind() != reflect.Func || t.NumOut() == 0 || t.Out(0) == typeOfError {
+			return nil, fmt.Errorf("expected a dig constructor returning a value, but found %T", constructor)
+		}
+		name := t.Out(0).String()
+		if _, ok := components[name]; ok {
+			return nil, fmt.Errorf("multiple constructors return %s", name)
+		}
+		components[name] = acyclicloader.Annotate(constructor, acyclicloader.ParamsByType())
+	}
+	return components, nil
+}
@@ -0,0 +1,79 @@
+package acyclicdig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+type Database struct{ dsn string }
+type UserModel struct{ db *Database }
+
+func TestProviders(t *testing.T) {
+	loader := acyclicloader.Components{
+		"Database": func() *Database { return &Database{dsn: "postgres://"} },
+		"Port":     80,
+		"Backup":   8080,
+		"Failing":  func() (string, error) { return "", errors.New("failed") },
+		"Migrate":  func() error { return nil },
+	}.AsLoader()
+
+	providers, err := Providers(loader)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(providers) != 2 {
+		t.Fatal("expected providers for 'Database' and 'Failing', got: ", len(providers))
+	}
+	newDatabase, ok := providers[0].(func() (*Database, error))
+	if !ok {
+		t.Fatalf("unexpected provider type: %T", providers[0])
+	}
+	db, err := newDatabase()
+	if err != nil || db != loader.MustLoad("Database") {
+		t.Error("expected the provider to return the value cached by the loader")
+	}
+	if _, err := providers[1].(func() (string, error))(); err == nil {
+		t.Error("expected an error from 'Failing'")
+	}
+
+	_, err = Providers(loader, "Port", "Backup")
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "same type int") {
+		t.Error("expected an error for components with the same type")
+	}
+	if _, err := Providers(loader, "Migrate"); err == nil {
+		t.Error("expected an error for a component without a result")
+	}
+	if _, err := Providers(loader, "Undefined"); err == nil {
+		t.Error("expected an error for an undefined component")
+	}
+}
+
+func TestComponents(t *testing.T) {
+	components, err := Components(
+		func() *Database { return &Database{dsn: "postgres://"} },
+		func(db *Database) (*UserModel, error) { return &UserModel{db: db}, nil },
+	)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if names := strings.Join(components.Names(), ", "); names != "*acyclicdig.Database, *acyclicdig.UserModel" {
+		t.Error("unexpected names: ", names)
+	}
+	users := components.MustLoad("*acyclicdig.UserModel").(*UserModel)
+	if users.db.dsn != "postgres://" {
+		t.Error("expected the database to be wired by type")
+	}
+
+	_, err = Components(func() int { return 1 }, func() (int, error) { return 2, nil })
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for constructors returning the same type")
+	}
+	if _, err := Components(42); err == nil {
+		t.Error("expected an error for a constructor that isn't a function")
+	}
+}
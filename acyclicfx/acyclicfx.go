@@ -0,0 +1,73 @@
+// Package acyclicfx bridges an AcyclicLoader into a go.uber.org/fx application,
+// such that components can be adopted incrementally.
+//
+// Components are provided to fx as constructors from acyclicdig.Providers(),
+// and the loader is tied to the fx lifecycle with Lifecycle(), loading roots
+// when the application starts and shutting down the loader when it stops:
+//
+//   providers, err := acyclicdig.Providers(loader, "Database", "Logger")
+//   app := fx.New(
+//       fx.Provide(providers...),
+//       fx.Invoke(func(lc fx.Lifecycle) {
+//           hook := acyclicfx.Lifecycle(loader, "Server")
+//           lc.Append(fx.Hook{OnStart: hook.OnStart, OnStop: hook.OnStop})
+//       }),
+//   )
+//
+// This package doesn't import fx, such that depending on this module doesn't
+// pull in fx and its dependencies.
+package acyclicfx
+
+import (
+	"context"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+// A Hook holds functions for fx.Hook, see Lifecycle().
+type Hook struct {
+	OnStart func(context.Context) error
+	OnStop  func(context.Context) error
+}
+
+// Lifecycle returns a Hook that loads roots concurrently on start, and shuts
+// down loader on stop, closing loaded components as in Shutdown().
+//
+// If the context given by fx is done before roots are loaded, or before the
+// loader is shut down, the context error is returned, while loading or shutting
+// down continues in the background.
+func Lifecycle(loader *acyclicloader.AcyclicLoader, roots ...string) Hook {
+	return Hook{
+		OnStart: func(ctx context.Context) error {
+			return wait(ctx, func() error {
+				for _, root := range roots {
+					go loader.LoadContext(ctx, root)
+				}
+				for _, root := range roots {
+					if _, err := loader.LoadContext(ctx, root); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+		OnStop: func(ctx context.Context) error {
+			return wait(ctx, loader.Shutdown)
+		},
+	}
+}
+
+// wait returns the result of calling fn, or the error from ctx, if ctx is done
+// before fn returns.
+func wait(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,63 @@
+package acyclicfx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+type closer struct{ closed bool }
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+	loader := acyclicloader.Components{
+		"Database": func() *closer { return &closer{} },
+		"Server":   func(options struct{ Database *closer }) int { return 80 },
+		"Unused":   func() *closer { return &closer{} },
+	}.AsLoader()
+
+	hook := Lifecycle(loader, "Server")
+	if err := hook.OnStart(context.Background()); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if !loader.Loaded("Server") || !loader.Loaded("Database") || loader.Loaded("Unused") {
+		t.Error("expected roots and their dependencies to be loaded on start")
+	}
+	db := loader.MustLoad("Database").(*closer)
+	if err := hook.OnStop(context.Background()); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if !db.closed {
+		t.Error("expected components to be closed on stop")
+	}
+}
+
+func TestLifecycleErrors(t *testing.T) {
+	failure := errors.New("connection refused")
+	release := make(chan struct{})
+	loader := acyclicloader.Components{
+		"Failing": func() (int, error) { return 0, failure },
+		"Slow": func() int {
+			<-release
+			return 1
+		},
+	}.AsLoader()
+	defer close(release)
+
+	if err := Lifecycle(loader, "Failing").OnStart(context.Background()); !errors.Is(err, failure) {
+		t.Error("expected the error from loading 'Failing', got: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := Lifecycle(loader, "Slow").OnStart(ctx); err != context.DeadlineExceeded {
+		t.Error("expected start to time out, got: ", err)
+	}
+}
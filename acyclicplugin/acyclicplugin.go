@@ -0,0 +1,74 @@
+// Package acyclicplugin discovers components from Go plugins in a directory,
+// such that integrations can be added to a deployment without rebuilding the
+// host binary.
+//
+// Each plugin must be built with -buildmode=plugin against the same version of
+// acyclicloader as the host, and export a variable named Components:
+//
+//   // In the plugin, built as plugins/slack.so
+//   var Components = acyclicloader.Components{
+//       "Notifier": func(options struct{ Config *Config }) *SlackNotifier { ... },
+//   }
+//
+//   // In the host
+//   components, err := acyclicplugin.Load(hostComponents, "plugins/")
+//
+// Components from a plugin are mounted under the name of the plugin file, so
+// "Notifier" above becomes "slack/Notifier", and may depend on components from
+// the host, see Components.Mount().
+package acyclicplugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"strings"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+// Symbol is the name of the variable each plugin must export.
+const Symbol = "Components"
+
+// Load returns a new set of components holding the components from host and
+// from each plugin with the extension .so in dir, ordered by file name. The
+// components of a plugin are mounted under its file name without extension.
+//
+// An error is returned if a plugin can't be opened, doesn't export Components
+// with type acyclicloader.Components, or defines components conflicting with
+// host. Plugins are only supported on some platforms, see package plugin.
+func Load(host acyclicloader.Components, dir string) (acyclicloader.Components, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin directory %s: %w", dir, err)
+	}
+	components, _ := host.Merge(nil) // copy, so host is never modified
+	for _, file := range files {     // Glob returns files ordered by name
+		p, err := plugin.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open plugin %s: %w", file, err)
+		}
+		symbol, err := p.Lookup(Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plugin %s: %w", file, err)
+		}
+		namespace := strings.TrimSuffix(filepath.Base(file), ".so")
+		if components, err = mount(components, namespace, symbol); err != nil {
+			return nil, fmt.Errorf("invalid plugin %s: %w", file, err)
+		}
+	}
+	return components, nil
+}
+
+// mount returns components with the components exported by a plugin as
+// symbol mounted under namespace.
+func mount(components acyclicloader.Components, namespace string, symbol plugin.Symbol) (acyclicloader.Components, error) {
+	// Lookup returns a pointer to exported variables
+	exported, ok := symbol.(*acyclicloader.Components)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%s must have type acyclicloader.Components, but has type %T", Symbol, symbol,
+		)
+	}
+	return components.Mount(namespace, *exported)
+}
@@ -0,0 +1,74 @@
+package acyclicplugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+func TestLoadEmptyDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acyclicplugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	host := acyclicloader.Components{"Config": func() string { return "config" }}
+	components, err := Load(host, dir)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if names := components.Names(); len(names) != 1 || names[0] != "Config" {
+		t.Error("expected only host components, got: ", names)
+	}
+}
+
+func TestLoadInvalidPlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acyclicplugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "broken.so")
+	if err := ioutil.WriteFile(file, []byte("not a plugin"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Load(acyclicloader.Components{}, dir)
+	if err == nil || !strings.Contains(err.Error(), file) {
+		t.Error("expected an error mentioning the plugin, got: ", err)
+	}
+}
+
+func TestMount(t *testing.T) {
+	host := acyclicloader.Components{"Config": func() string { return "config" }}
+	exported := acyclicloader.Components{
+		"Notifier": func(options struct{ Config string }) string {
+			return "notify with " + options.Config
+		},
+	}
+
+	components, err := mount(host, "slack", &exported)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	value := components.AsLoader().MustLoad("slack/Notifier")
+	if value != "notify with config" {
+		t.Error("expected plugin to depend on host component, got: ", value)
+	}
+	if len(host) != 1 {
+		t.Error("expected host components not to be modified")
+	}
+
+	if _, err := mount(host, "slack", exported); err == nil ||
+		!strings.Contains(err.Error(), "acyclicloader.Components") {
+		t.Error("expected an error for a symbol with the wrong type, got: ", err)
+	}
+	if _, err := mount(components, "slack", &exported); err == nil {
+		t.Error("expected an error for conflicting components")
+	}
+}
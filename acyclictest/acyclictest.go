@@ -0,0 +1,69 @@
+// Package acyclictest provides assertions for testing code using an
+// AcyclicLoader, such as asserting that a code path doesn't load heavy
+// components:
+//
+//   func TestHealthHandler(t *testing.T) {
+//       clone := loader.Clone()
+//       recorder := clone.Recorder()
+//       handler := clone.MustLoad("HealthHandler").(http.Handler)
+//       ...
+//       acyclictest.AssertNotLoaded(t, clone, "TemplateEngine", "Database")
+//       acyclictest.AssertLoadOrderRespectsDeps(t, recorder)
+//   }
+package acyclictest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+// AssertLoaded fails the test, if any of the given components hasn't been
+// loaded by loader.
+func AssertLoaded(t testing.TB, loader *acyclicloader.AcyclicLoader, components ...string) {
+	t.Helper()
+	var missing []string
+	for _, name := range components {
+		if !loader.Loaded(name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		t.Errorf("expected components to be loaded: '%s'", strings.Join(missing, "', '"))
+	}
+}
+
+// AssertNotLoaded fails the test, if any of the given components has been
+// loaded by loader.
+func AssertNotLoaded(t testing.TB, loader *acyclicloader.AcyclicLoader, components ...string) {
+	t.Helper()
+	var loaded []string
+	for _, name := range components {
+		if loader.Loaded(name) {
+			loaded = append(loaded, name)
+		}
+	}
+	if len(loaded) > 0 {
+		t.Errorf("expected components not to be loaded: '%s'", strings.Join(loaded, "', '"))
+	}
+}
+
+// AssertLoadOrderRespectsDeps fails the test, if recorder has recorded a
+// component finishing loading before a dependency it wasn't lazy about.
+// Dependencies loaded before recording started are ignored.
+func AssertLoadOrderRespectsDeps(t testing.TB, recorder *acyclicloader.Recorder) {
+	t.Helper()
+	loads := recorder.Loads()
+	first := make(map[string]int, len(loads))
+	for i := len(loads) - 1; i >= 0; i-- {
+		first[loads[i].Component] = i
+	}
+	for i, load := range loads {
+		for _, dep := range load.Dependencies {
+			if j, ok := first[dep]; ok && j > i {
+				t.Errorf("'%s' finished loading before its dependency '%s'", load.Component, dep)
+			}
+		}
+	}
+}
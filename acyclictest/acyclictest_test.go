@@ -0,0 +1,43 @@
+package acyclictest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+// recordingT records failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	loader := acyclicloader.Components{
+		"Database":  func() string { return "database" },
+		"Templates": func() string { return "templates" },
+		"Handler": func(options struct{ Database string }) string {
+			return "handler"
+		},
+	}.AsLoader()
+	recorder := loader.Recorder()
+	loader.MustLoad("Handler")
+
+	AssertLoaded(t, loader, "Handler", "Database")
+	AssertNotLoaded(t, loader, "Templates")
+	AssertLoadOrderRespectsDeps(t, recorder)
+
+	rt := &recordingT{TB: t}
+	AssertLoaded(rt, loader, "Templates")
+	AssertNotLoaded(rt, loader, "Handler", "Database")
+	if len(rt.errors) != 2 || rt.errors[1] != "expected components not to be loaded: 'Handler', 'Database'" {
+		t.Error("unexpected failures: ", rt.errors)
+	}
+}
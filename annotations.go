@@ -0,0 +1,283 @@
+package acyclicloader
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Annotated holds a function loading a component along with annotations, see
+// Annotate().
+type Annotated struct {
+	fn          interface{}
+	annotations []Annotation
+}
+
+// An Annotation modifies how a component is loaded, see Annotate().
+type Annotation func(*component)
+
+// Annotate wraps the function loading a component with annotations, the
+// result can be used in place of the function in Components.
+//
+//   "Template": acyclicloader.Annotate(func() *Template {
+//       return NewTemplate()
+//   }, acyclicloader.NotGoroutineSafe()),
+func Annotate(fn interface{}, annotations ...Annotation) *Annotated {
+	return &Annotated{
+		fn:          fn,
+		annotations: annotations,
+	}
+}
+
+// NotGoroutineSafe marks a component as unsafe for concurrent use.
+//
+// If two components depending on such a component may be loaded concurrently,
+// New() will report a warning, or return an error in strict mode. This can be
+// resolved using SerializeDependents() or AcknowledgeConcurrentUse().
+func NotGoroutineSafe() Annotation {
+	return func(c *component) {
+		c.notGoroutineSafe = true
+	}
+}
+
+// SerializeDependents marks a component as unsafe for concurrent use, and
+// ensures that components depending on it are never loaded concurrently.
+//
+// Clones of the loader, such as those returned by WithOverwrites(), and child
+// loaders share the lock serializing dependents. This is intended, as they may
+// share the cached value, but it means dependents in independent clones are
+// serialized against each other too.
+func SerializeDependents() Annotation {
+	return func(c *component) {
+		c.notGoroutineSafe = true
+		c.serialize = &sync.Mutex{}
+	}
+}
+
+// AcknowledgeConcurrentUse acknowledges that a component uses the given
+// dependencies in a goroutine-safe manner, even though they are marked
+// NotGoroutineSafe().
+func AcknowledgeConcurrentUse(dependencies ...string) Annotation {
+	return func(c *component) {
+		c.acknowledged = append(c.acknowledged, dependencies...)
+	}
+}
+
+// Tagged attaches tags to a component, such that it can be loaded or shut down
+// along with other components sharing a tag, see LoadTagged() and
+// ShutdownTagged().
+func Tagged(tags ...string) Annotation {
+	return func(c *component) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// EnabledBy gates a component behind a feature flag, flag must be the name of
+// a component with type bool. The flag is loaded before the component, and if
+// it is false, the component is disabled and its dependencies aren't loaded.
+//
+// Loading a disabled component returns a DisabledComponentError. Dependents
+// declaring it optional get the zero value, other dependents fail to load.
+//
+//   "Recommendations": acyclicloader.Annotate(func() *Recommender {
+//       return NewRecommender()
+//   }, acyclicloader.EnabledBy("RecommendationsEnabled")),
+func EnabledBy(flag string) Annotation {
+	return func(c *component) {
+		c.enabledBy = flag
+	}
+}
+
+// TTL makes a component expire ttl after it was loaded, such that the next
+// Load() calls the function loading the component again. This is useful for
+// components such as tokens, discovery data or remote configuration.
+//
+// Dependents loaded before the component expires keep the old value, use a
+// lazy dependency to obtain the current value whenever it is needed.
+func TTL(ttl time.Duration) Annotation {
+	return func(c *component) {
+		c.ttl = ttl
+	}
+}
+
+// WatchFiles declares files used by a component, such as templates or
+// certificates, such that AcyclicLoader.Watch() refreshes the component when
+// the files are modified.
+func WatchFiles(paths ...string) Annotation {
+	return func(c *component) {
+		c.watched = append(c.watched, paths...)
+	}
+}
+
+// Transient makes a component constructed again every time it is loaded,
+// rather than cached. Each dependent gets its own value, which is useful for
+// objects such as per-job workers built from shared components.
+//
+// Values of transient components are owned by the caller, and are never closed
+// by Shutdown().
+func Transient() Annotation {
+	return func(c *component) {
+		c.transient = true
+	}
+}
+
+// ParamsByType makes the parameters of the function loading a component wired
+// by type, even if it takes a single struct or pointer to a struct, which would
+// otherwise be treated as an options struct. This is useful for constructors
+// written for other dependency injection frameworks:
+//   "Users": Annotate(NewUserModel, ParamsByType()), // func(*sql.DB) *UserModel
+func ParamsByType() Annotation {
+	return func(c *component) {
+		c.paramsByType = true
+	}
+}
+
+// RenameDependency makes the dependency declared by field in the options
+// struct load the component named name, rather than the component named by the
+// field or its tag. This allows wiring a function to different components,
+// without defining a new options struct:
+//   "ReplicaUsers": Annotate(NewUserModel, RenameDependency("Database", "Replica")),
+//
+// Renamed dependencies are wired by name, even if they would otherwise be wired
+// by type. Plain parameters are declared by fields named "Param0", "Param1" and
+// so on.
+func RenameDependency(field, name string) Annotation {
+	return func(c *component) {
+		renamed := make(map[string]string, len(c.renamed)+1)
+		for f, n := range c.renamed {
+			renamed[f] = n
+		}
+		renamed[field] = name
+		c.renamed = renamed
+	}
+}
+
+// Version declares a function returning the version of the inputs a component
+// is loaded from, such as a hash of a configuration file, see HashFiles(). The
+// version is obtained when the component is loaded, and cached values are
+// loaded again by RefreshChanged(), or skipped by RestoreSnapshot(), if the
+// version has changed.
+func Version(fn func() string) Annotation {
+	return func(c *component) {
+		c.versionFunc = fn
+	}
+}
+
+// LimitedBy makes the function loading a component wait for the named
+// semaphore, such that the number of such functions running concurrently is
+// limited, see WithSemaphore(). Time spent waiting isn't included in the load
+// duration. A function holding a semaphore must not load other components
+// limited by the same semaphore, as this may deadlock.
+//
+//   loader := acyclicloader.Components{
+//       "Geocoder": Annotate(NewGeocoder, LimitedBy("external-api")),
+//       "Payments": Annotate(NewPayments, LimitedBy("external-api")),
+//   }.AsLoader(acyclicloader.WithSemaphore("external-api", 1))
+func LimitedBy(semaphore string) Annotation {
+	return func(c *component) {
+		c.semaphore = semaphore
+	}
+}
+
+// Priority sets the priority of a component, such that it is loaded before
+// components with lower priority, when more components are ready to load than
+// allowed by WithMaxConcurrency(). The default priority is zero, components on
+// the critical path to serving traffic, see CriticalPath(), should have higher
+// priority.
+func Priority(priority int) Annotation {
+	return func(c *component) {
+		c.priority = priority
+	}
+}
+
+// After makes a component load after the given components, without using
+// their values, such as running "Migrations" before "Server" without declaring
+// a dependency field. The component fails to load, if any of them fail, and
+// ordering constraints are checked for cycles like other dependencies.
+//
+//   "Server": Annotate(NewServer, After("Migrations")),
+func After(components ...string) Annotation {
+	return func(c *component) {
+		c.after = append(c.after[:len(c.after):len(c.after)], components...)
+	}
+}
+
+// AllowNil allows the function loading a component to return nil without an
+// error, when the loader is created with WithNilResultsAsErrors(). This is
+// useful for optional clients, where nil means disabled.
+func AllowNil() Annotation {
+	return func(c *component) {
+		c.allowNil = true
+	}
+}
+
+// Scoped makes a component cached per loader, such that each child loader
+// created with Child() loads its own value, using dependencies from the child
+// loader. Components inherited by a child loader, that depend on a scoped
+// component, still use the value from the parent loader.
+func Scoped() Annotation {
+	return func(c *component) {
+		c.scoped = true
+	}
+}
+
+// Keyed makes a component parameterized by a key, such as a tenant or queue
+// name, the function loading the component must take the key as its first
+// parameter, on the form:
+//   func (key string, struct{Dependency DependencyType, ...}) (ComponentType, error)
+//
+// The component is loaded and cached once per key, see LoadKeyed(). Dependents
+// must declare the dependency as a getter on the form:
+//   func(key string) (ComponentType, error)
+//
+// If a keyed component is overwritten with WithOverwrites(), the value given is
+// returned for every key.
+func Keyed() Annotation {
+	return func(c *component) {
+		c.keyed = true
+	}
+}
+
+// Pool makes a component a pool of size instances, which are loaded
+// concurrently by calling the function loading the component size times. The
+// type of the component is a slice of instances.
+//
+// Dependents may declare the dependency as the slice, or as an accessor on the
+// form func() ComponentType returning instances in round-robin order.
+//   "Connections": acyclicloader.Annotate(func() (*grpc.ClientConn, error) {
+//       return grpc.Dial(address)
+//   }, acyclicloader.Pool(4)),
+//   "Client": func(options struct {
+//       Connections func() *grpc.ClientConn
+//   }) *Client { ... },
+func Pool(size int) Annotation {
+	return func(c *component) {
+		c.pool = size
+		if c.result != nil && c.element == nil {
+			c.element = c.result
+			c.result = reflect.SliceOf(c.result)
+		}
+	}
+}
+
+// CircuitBreaker makes errors from loading a component cached only after the
+// function loading it has failed threshold times in a row. Until then, each
+// Load() tries to load the component again. Once the circuit is open, Load()
+// returns the cached error until cooldown has passed, after which the next
+// Load() tries again, and a success closes the circuit.
+//
+// This is useful for components connecting to remote services, as goroutines
+// loading the component concurrently won't hammer a service that is down.
+func CircuitBreaker(threshold int, cooldown time.Duration) Annotation {
+	return CircuitBreakerBackoff(threshold, ConstantBackoff(cooldown))
+}
+
+// CircuitBreakerBackoff is the same as CircuitBreaker(), but the circuit stays
+// open for a duration determined by backoff, given the number of times the
+// circuit has opened without the component loading successfully.
+func CircuitBreakerBackoff(threshold int, backoff Backoff) Annotation {
+	return func(c *component) {
+		c.threshold = threshold
+		c.cooldown = backoff
+	}
+}
@@ -0,0 +1,398 @@
+package acyclicloader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestNotGoroutineSafe(t *testing.T) {
+	components := Components{
+		"Unsafe": Annotate(func() int { return 5 }, NotGoroutineSafe()),
+		"A":      func(options struct{ Unsafe int }) int { return options.Unsafe },
+		"B":      func(options struct{ Unsafe int }) int { return options.Unsafe },
+	}
+
+	logger := &testLogger{}
+	_, err := New(components, WithLogger(logger))
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(logger.messages) != 1 {
+		t.Fatal("expected a warning, got: ", logger.messages)
+	}
+	t.Logf("got warning as expected: '%s'", logger.messages[0])
+
+	_, err = New(components, WithStrictMode())
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestNotGoroutineSafeSequentialDependents(t *testing.T) {
+	logger := &testLogger{}
+	_, err := New(Components{
+		"Unsafe": Annotate(func() int { return 5 }, NotGoroutineSafe()),
+		"A":      func(options struct{ Unsafe int }) int { return options.Unsafe },
+		"B": func(options struct {
+			Unsafe int
+			A      int
+		}) int {
+			return options.Unsafe + options.A
+		},
+	}, WithLogger(logger), WithStrictMode())
+	if err != nil {
+		t.Error("unexpected error: ", err)
+	}
+}
+
+func TestNotGoroutineSafeDiamonds(t *testing.T) {
+	// A chain of diamonds has exponentially many paths, which must not be
+	// explored when checking whether dependents may be loaded concurrently
+	components := Components{
+		"Unsafe": Annotate(func() int { return 5 }, NotGoroutineSafe()),
+		"L0":     func() int { return 0 },
+		"Z": func(options struct {
+			Unsafe int
+			Top    int
+		}) int {
+			return options.Top
+		},
+	}
+	layer := func(prev string) interface{} {
+		return Annotate(func(options struct{ Prev int }) int { return options.Prev }, RenameDependency("Prev", prev))
+	}
+	for i := 1; i <= 40; i++ {
+		components[fmt.Sprintf("A%d", i)] = layer(fmt.Sprintf("L%d", i-1))
+		components[fmt.Sprintf("B%d", i)] = layer(fmt.Sprintf("L%d", i-1))
+		components[fmt.Sprintf("L%d", i)] = Annotate(func(options struct{ A, B int }) int {
+			return options.A + options.B
+		}, RenameDependency("A", fmt.Sprintf("A%d", i)), RenameDependency("B", fmt.Sprintf("B%d", i)))
+	}
+	components["Top"] = Annotate(func(options struct{ Unsafe, Last int }) int {
+		return options.Last
+	}, RenameDependency("Last", "L40"))
+
+	if _, err := New(components, WithStrictMode()); err != nil {
+		t.Error("unexpected error: ", err)
+	}
+}
+
+func TestAcknowledgeConcurrentUse(t *testing.T) {
+	_, err := New(Components{
+		"Unsafe": Annotate(func() int { return 5 }, NotGoroutineSafe()),
+		"A":      func(options struct{ Unsafe int }) int { return options.Unsafe },
+		"B": Annotate(func(options struct{ Unsafe int }) int {
+			return options.Unsafe
+		}, AcknowledgeConcurrentUse("Unsafe")),
+	}, WithStrictMode())
+	if err != nil {
+		t.Error("unexpected error: ", err)
+	}
+
+	_, err = New(Components{
+		"A": Annotate(func() int { return 5 }, AcknowledgeConcurrentUse("B")),
+		"B": func() int { return 5 },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSerializeDependents(t *testing.T) {
+	var running, overlaps int32
+	dependent := func(options struct{ Unsafe int }) int {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return options.Unsafe
+	}
+	loader, err := New(Components{
+		"Unsafe": Annotate(func() int { return 5 }, SerializeDependents()),
+		"A":      dependent,
+		"B":      dependent,
+		"C":      dependent,
+		"Root": func(options struct{ A, B, C int }) int {
+			return options.A + options.B + options.C
+		},
+	}, WithStrictMode())
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Root").(int) != 15 {
+		t.Error("expected 15")
+	}
+	if overlaps != 0 {
+		t.Error("expected dependents to be loaded sequentially")
+	}
+}
+
+func TestEnabledBy(t *testing.T) {
+	var constructed int32
+	components := Components{
+		"Enabled": func() bool { return false },
+		"Heavy": func() int {
+			atomic.AddInt32(&constructed, 1)
+			return 42
+		},
+		"Feature": Annotate(func(options struct{ Heavy int }) int {
+			atomic.AddInt32(&constructed, 1)
+			return options.Heavy
+		}, EnabledBy("Enabled")),
+		"Optional": func(options struct {
+			Feature int `component:",optional"`
+		}) int {
+			return options.Feature + 1
+		},
+		"Required": func(options struct{ Feature int }) int {
+			return options.Feature
+		},
+	}
+	loader, err := New(components)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Optional").(int) != 1 {
+		t.Error("expected zero value for disabled optional dependency")
+	}
+	_, err = loader.Load("Required")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := errors.Unwrap(err).(*DisabledComponentError); !ok {
+		t.Error("expected a DisabledComponentError")
+	}
+	if constructed != 0 {
+		t.Error("expected disabled component and its dependencies not to be loaded")
+	}
+
+	loader, err = loader.WithOverwrites(map[string]interface{}{"Enabled": true})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Optional").(int) != 43 {
+		t.Error("expected enabled component to be loaded")
+	}
+
+	components["Enabled"] = func() string { return "yes" }
+	_, err = New(components)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for non-bool flag")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	var tokens int32
+	loader := Components{
+		"Token": Annotate(func() int32 {
+			return atomic.AddInt32(&tokens, 1)
+		}, TTL(20*time.Millisecond)),
+		"Client": func(options struct {
+			Token func() (int32, error) `component:",lazy"`
+		}) func() (int32, error) {
+			return options.Token
+		},
+	}.AsLoader()
+	if loader.MustLoad("Token").(int32) != 1 || loader.MustLoad("Token").(int32) != 1 {
+		t.Error("expected 'Token' to be cached before expiry")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if loader.MustLoad("Token").(int32) != 2 {
+		t.Error("expected 'Token' to be loaded again after expiry")
+	}
+
+	getToken := loader.MustLoad("Client").(func() (int32, error))
+	time.Sleep(30 * time.Millisecond)
+	if token, err := getToken(); err != nil || token != 3 {
+		t.Error("expected lazy getter to return the new value, got: ", token, err)
+	}
+}
+
+func TestTransient(t *testing.T) {
+	var workers int32
+	loader := Components{
+		"Worker": Annotate(func() int32 {
+			return atomic.AddInt32(&workers, 1)
+		}, Transient()),
+		"A": func(options struct{ Worker int32 }) int32 { return options.Worker },
+		"B": func(options struct{ Worker int32 }) int32 { return options.Worker },
+		"Root": func(options struct{ A, B int32 }) int32 {
+			return options.A + options.B
+		},
+	}.AsLoader()
+	if loader.MustLoad("Root").(int32) != 3 {
+		t.Error("expected 'A' and 'B' to get different workers")
+	}
+	if loader.MustLoad("Worker").(int32) != 3 || loader.MustLoad("Worker").(int32) != 4 {
+		t.Error("expected a new worker for each load")
+	}
+}
+
+func TestScoped(t *testing.T) {
+	var sessions int32
+	parent := Components{
+		"User": func() string { return "anonymous" },
+		"Session": Annotate(func(options struct{ User string }) string {
+			atomic.AddInt32(&sessions, 1)
+			return options.User
+		}, Scoped()),
+	}.AsLoader()
+	if parent.MustLoad("Session").(string) != "anonymous" {
+		t.Error("expected 'anonymous'")
+	}
+
+	child, err := parent.Child(Components{
+		"User": func() string { return "alice" },
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if child.MustLoad("Session").(string) != "alice" {
+		t.Error("expected child to load its own 'Session'")
+	}
+	grandchild, err := child.Child(Components{})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	grandchild.MustLoad("Session")
+	child.MustLoad("Session")
+	if sessions != 3 {
+		t.Error("expected 'Session' to be loaded once per loader, got: ", sessions)
+	}
+}
+
+func TestRenameDependency(t *testing.T) {
+	user := func(options struct{ Database string }) string {
+		return "user from " + options.Database
+	}
+	loader := Components{
+		"Primary":      func() string { return "primary" },
+		"Replica":      func() string { return "replica" },
+		"Users":        Annotate(user, RenameDependency("Database", "Primary")),
+		"ReplicaUsers": Annotate(user, RenameDependency("Database", "Replica")),
+	}.AsLoader()
+	if loader.MustLoad("Users").(string) != "user from primary" {
+		t.Error("expected 'Users' to depend on 'Primary'")
+	}
+	if loader.MustLoad("ReplicaUsers").(string) != "user from replica" {
+		t.Error("expected 'ReplicaUsers' to depend on 'Replica'")
+	}
+
+	_, err := New(Components{
+		"Replica": func() string { return "replica" },
+		"Users":   Annotate(user, RenameDependency("DB", "Replica")),
+	})
+	if _, ok := err.(*ComponentDefinitionError); !ok {
+		t.Error("expected a ComponentDefinitionError for renaming a missing field, got: ", err)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	var migrated int32
+	failure := errors.New("migration failed")
+	components := Components{
+		"Migrations": func() error {
+			atomic.StoreInt32(&migrated, 1)
+			return nil
+		},
+		"Server": Annotate(func() bool {
+			return atomic.LoadInt32(&migrated) == 1
+		}, After("Migrations")),
+	}
+	if !components.AsLoader().MustLoad("Server").(bool) {
+		t.Error("expected 'Migrations' to load before 'Server'")
+	}
+
+	loader := Components{
+		"Migrations": func() error { return failure },
+		"Server":     Annotate(func() bool { return true }, After("Migrations")),
+	}.AsLoader()
+	if _, err := loader.Load("Server"); !errors.Is(err, failure) {
+		t.Error("expected 'Server' to fail with 'Migrations', got: ", err)
+	}
+
+	_, err := New(Components{
+		"Migrations": Annotate(func() error { return nil }, After("Server")),
+		"Server":     Annotate(func() bool { return true }, After("Migrations")),
+	})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Error("expected a dependency cycle, got: ", err)
+	}
+	if _, err := New(Components{
+		"Server": Annotate(func() bool { return true }, After("Migration")),
+	}); err == nil {
+		t.Error("expected an error for undefined components")
+	}
+}
+
+func TestAllowNil(t *testing.T) {
+	loader := Components{
+		"Client":   func() *strings.Builder { return nil },
+		"Optional": Annotate(func() *strings.Builder { return nil }, AllowNil()),
+		"Names":    func() []string { return nil },
+		"Service": func(options struct{ Client *strings.Builder }) string {
+			return options.Client.String()
+		},
+	}.AsLoader(WithNilResultsAsErrors())
+
+	_, err := loader.Load("Service")
+	var nilErr *NilResultError
+	if !errors.As(err, &nilErr) || nilErr.Component != "Client" {
+		t.Error("expected a NilResultError for 'Client', got: ", err)
+	}
+	if _, err := loader.Load("Optional"); err != nil {
+		t.Error("expected nil to be allowed for 'Optional', got: ", err)
+	}
+	if _, err := loader.Load("Names"); err != nil {
+		t.Error("expected nil slices to be allowed, got: ", err)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var attempts int32
+	var up int32
+	loader := Components{
+		"Remote": Annotate(func() (int32, error) {
+			n := atomic.AddInt32(&attempts, 1)
+			if atomic.LoadInt32(&up) == 0 {
+				return 0, errors.New("connection refused")
+			}
+			return n, nil
+		}, CircuitBreaker(3, 20*time.Millisecond)),
+		"Client": func(options struct{ Remote int32 }) int32 {
+			return options.Remote
+		},
+	}.AsLoader()
+
+	for i := 0; i < 5; i++ {
+		if _, err := loader.Load("Client"); err == nil {
+			t.Fatal("expected error while 'Remote' is down")
+		}
+	}
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Error("expected circuit to open after 3 attempts, got: ", n)
+	}
+
+	atomic.StoreInt32(&up, 1)
+	if _, err := loader.Load("Client"); err == nil {
+		t.Error("expected cached error while circuit is open")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if v, err := loader.Load("Client"); err != nil || v.(int32) != 4 {
+		t.Error("expected 'Client' to load after cooldown, got: ", v, err)
+	}
+}
@@ -0,0 +1,55 @@
+package acyclicloader
+
+import (
+	"math/rand"
+	"time"
+)
+
+// A Backoff determines how long to wait before trying again, after a number
+// of consecutive failures, see Supervise() and CircuitBreakerBackoff().
+type Backoff interface {
+	// Delay returns the time to wait after the given number of failures, which
+	// is always at least 1.
+	Delay(failures int) time.Duration
+}
+
+// A BackoffFunc is a function implementing Backoff.
+type BackoffFunc func(failures int) time.Duration
+
+// Delay returns f(failures).
+func (f BackoffFunc) Delay(failures int) time.Duration {
+	return f(failures)
+}
+
+// ConstantBackoff returns a Backoff that always waits d.
+func ConstantBackoff(d time.Duration) Backoff {
+	return BackoffFunc(func(int) time.Duration {
+		return d
+	})
+}
+
+// ExponentialBackoff returns a Backoff that waits initial after the first
+// failure, and doubles the delay for each failure after that, up to max.
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return BackoffFunc(func(failures int) time.Duration {
+		d := initial
+		for i := 1; i < failures && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	})
+}
+
+// JitteredBackoff returns a Backoff that randomizes delays from b by up to
+// ±factor, such that a factor of 0.2 returns delays between 80% and 120% of
+// the delay from b. This avoids processes retrying in lockstep, when a shared
+// upstream is down.
+func JitteredBackoff(b Backoff, factor float64) Backoff {
+	return BackoffFunc(func(failures int) time.Duration {
+		d := float64(b.Delay(failures))
+		return time.Duration(d + d*factor*(2*rand.Float64()-1))
+	})
+}
@@ -0,0 +1,51 @@
+package acyclicloader
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	if d := ConstantBackoff(time.Second).Delay(5); d != time.Second {
+		t.Error("expected constant delay, got: ", d)
+	}
+
+	exponential := ExponentialBackoff(time.Second, 10*time.Second)
+	for failures, want := range map[int]time.Duration{
+		1:   time.Second,
+		2:   2 * time.Second,
+		4:   8 * time.Second,
+		5:   10 * time.Second,
+		100: 10 * time.Second,
+	} {
+		if d := exponential.Delay(failures); d != want {
+			t.Errorf("expected %s after %d failures, got %s", want, failures, d)
+		}
+	}
+
+	jittered := JitteredBackoff(ConstantBackoff(time.Second), 0.2)
+	for i := 0; i < 100; i++ {
+		if d := jittered.Delay(1); d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatal("expected delay within 20% of a second, got: ", d)
+		}
+	}
+}
+
+func TestCircuitBreakerBackoff(t *testing.T) {
+	var opened []int
+	loader := Components{
+		"Remote": Annotate(func() (int, error) {
+			return 0, errors.New("connection refused")
+		}, CircuitBreakerBackoff(1, BackoffFunc(func(n int) time.Duration {
+			opened = append(opened, n)
+			return 0
+		}))),
+	}.AsLoader()
+	for i := 0; i < 3; i++ {
+		loader.Load("Remote")
+	}
+	if len(opened) != 2 || opened[0] != 1 || opened[1] != 2 {
+		t.Error("expected backoff for each time the circuit opened, got: ", opened)
+	}
+}
@@ -0,0 +1,79 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Bind returns a new set of components holding the components from c and a
+// component name fulfilled by the component implementation, c is not modified.
+//
+// The bound component has the same value and type as implementation, but since
+// dependencies may be declared with any type the component is assignable to,
+// a dependent can declare it using an interface type:
+//
+//   components, err := acyclicloader.Components{
+//       "ZapLogger": func() *ZapLogger { ... },
+//       "Server": func(options struct{ Logger Logger }) *Server { ... },
+//   }.Bind("Logger", "ZapLogger")
+//
+// See Bind[I]() for declaring a component with an interface type. A
+// MergeConflictError is returned, if name is already defined in c.
+func (c Components) Bind(name, implementation string) (Components, error) {
+	fn, ok := c[implementation]
+	if !ok {
+		names := make([]string, 0, len(c))
+		for n := range c {
+			names = append(names, n)
+		}
+		names = sortedStrings(names)
+		return nil, &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"cannot bind '%s' to undefined component '%s'%s", name, implementation,
+				describeAlternatives(suggestNames(implementation, names), names),
+			),
+		}
+	}
+	if p, ok := fn.(*profiled); ok {
+		fn = p.variants()[0] // profiles are expected to agree on the type
+	}
+	impl, err := newComponent(implementation, fn)
+	if err != nil {
+		return nil, err
+	}
+	if impl.result == nil {
+		return nil, &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"cannot bind '%s' to '%s' as it doesn't have a result", name, implementation,
+			),
+		}
+	}
+	return c.Merge(Components{name: bindFunc(implementation, impl.result)})
+}
+
+// bindFunc returns a function on the form:
+//   func(struct{ Implementation T `component:"implementation"` }) T
+func bindFunc(implementation string, t reflect.Type) interface{} {
+	options := reflect.StructOf([]reflect.StructField{{
+		Name: "Implementation",
+		Type: t,
+		Tag:  reflect.StructTag("component:" + strconv.Quote(implementation)),
+	}})
+	ft := reflect.FuncOf([]reflect.Type{options}, []reflect.Type{t}, false)
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		return []reflect.Value{args[0].Field(0)}
+	}).Interface()
+}
+
+// Alias returns a new set of components holding the components from c and
+// alias referring to the component name, c is not modified. This is useful
+// when renaming a component, as dependents can be migrated one at a time.
+//
+// The alias is a component depending on name, so it is subject to cycle
+// detection like any other dependency. See Bind() for errors returned.
+func (c Components) Alias(alias, name string) (Components, error) {
+	return c.Bind(alias, name)
+}
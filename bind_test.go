@@ -0,0 +1,69 @@
+package acyclicloader
+
+import "testing"
+
+func TestBind(t *testing.T) {
+	components, err := Components{
+		"English": func() *englishGreeter { return &englishGreeter{} },
+		"Message": func(options struct{ Greeter testGreeter }) string {
+			return options.Greeter.Greet()
+		},
+	}.Bind("Greeter", "English")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	loader, err := New(components)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Message").(string) != "hello" {
+		t.Error("expected 'hello'")
+	}
+	if loader.MustLoad("Greeter") != loader.MustLoad("English") {
+		t.Error("expected 'Greeter' to be the same value as 'English'")
+	}
+
+	_, err = components.Bind("Greeter", "English")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*MergeConflictError); !ok {
+		t.Error("expected a MergeConflictError")
+	}
+
+	_, err = components.Bind("Other", "Englsh")
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestAlias(t *testing.T) {
+	components, err := Components{
+		"Database": func() *testLogger { return &testLogger{} },
+		"Users": func(options struct{ DB *testLogger }) int {
+			return len(options.DB.messages)
+		},
+	}.Alias("DB", "Database")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	loader, err := New(components)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("DB") != loader.MustLoad("Database") {
+		t.Error("expected 'DB' to be the same value as 'Database'")
+	}
+
+	// Aliases are counted by cycle detection
+	components, err = Components{
+		"A": func(options struct{ B int }) int { return options.B },
+	}.Alias("B", "A")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	_, err = New(components)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected a cycle to be detected")
+	}
+}
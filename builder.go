@@ -0,0 +1,151 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+)
+
+// A Builder builds an AcyclicLoader incrementally, as an alternative to
+// declaring all components in a single Components literal.
+//
+// Definitions are validated as they are added, and errors are reported with
+// the file and line where the component was added.
+//
+//   loader, err := acyclicloader.NewBuilder().
+//       AddValue("Port", 80).
+//       Add("Server", func(options struct{ Port int }) *http.Server {
+//           return &http.Server{Addr: fmt.Sprintf(":%d", options.Port)}
+//       }).
+//       Build()
+type Builder struct {
+	components Components
+	sites      map[string]string
+	errs       []error
+	options    []Option
+}
+
+// NewBuilder returns a Builder, options are passed to New() by Build().
+func NewBuilder(options ...Option) *Builder {
+	return &Builder{
+		components: Components{},
+		sites:      map[string]string{},
+		options:    options,
+	}
+}
+
+// Add a component to the Builder, see Components for valid definitions. Unlike
+// Components, constant values must be added with AddValue().
+func (b *Builder) Add(name string, fn interface{}) *Builder {
+	definition := fn
+	if an, ok := fn.(*Annotated); ok {
+		definition = an.fn
+	}
+	if t := reflect.TypeOf(definition); t != nil && t.Kind() != reflect.Func {
+		b.errs = append(b.errs, &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"%s: expected definition of '%s' to be a function, but found %s, use AddValue() for values",
+				callSite(1), name, t.String(),
+			),
+		})
+		return b
+	}
+	b.add(callSite(1), name, fn, false)
+	return b
+}
+
+// AddValue adds a component with a constant value to the Builder.
+func (b *Builder) AddValue(name string, value interface{}) *Builder {
+	if value == nil {
+		b.errs = append(b.errs, &ComponentDefinitionError{
+			Component: name,
+			message:   fmt.Sprintf("%s: cannot add nil as value of '%s'", callSite(1), name),
+		})
+		return b
+	}
+	b.add(callSite(1), name, valueFunc(value), false)
+	return b
+}
+
+// Override replaces the definition of a component previously added to the
+// Builder, this is useful for replacing components with mock objects in tests.
+func (b *Builder) Override(name string, fn interface{}) *Builder {
+	b.add(callSite(1), name, fn, true)
+	return b
+}
+
+func (b *Builder) add(site, name string, fn interface{}, override bool) {
+	_, defined := b.components[name]
+	if defined && !override {
+		b.errs = append(b.errs, &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"%s: '%s' is already defined at %s", site, name, b.sites[name],
+			),
+		})
+		return
+	}
+	if !defined && override {
+		b.errs = append(b.errs, &ComponentDefinitionError{
+			Component: name,
+			message:   fmt.Sprintf("%s: cannot override undefined component '%s'", site, name),
+		})
+		return
+	}
+	if _, err := newComponent(name, fn); err != nil {
+		b.errs = append(b.errs, atSite(site, err))
+		return
+	}
+	b.components[name] = fn
+	b.sites[name] = site
+}
+
+// Build returns an AcyclicLoader with the components added, or a BuildError
+// listing all errors encountered while adding components.
+func (b *Builder) Build() (*AcyclicLoader, error) {
+	if len(b.errs) > 0 {
+		return nil, &BuildError{Errors: b.errs}
+	}
+	a, err := New(b.components, b.options...)
+	if e, ok := err.(*ComponentDefinitionError); ok {
+		if site, ok := b.sites[e.Component]; ok {
+			err = atSite(site, e)
+		}
+	}
+	if err != nil {
+		return nil, &BuildError{Errors: []error{err}}
+	}
+	return a, nil
+}
+
+// valueFunc returns a function on the form func() T returning value.
+func valueFunc(value interface{}) interface{} {
+	t := reflect.TypeOf(value)
+	v := reflect.ValueOf(value)
+	return reflect.MakeFunc(reflect.FuncOf(nil, []reflect.Type{t}, false), func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{v}
+	}).Interface()
+}
+
+// callSite returns file:line for the caller skip frames above the caller of
+// callSite, this is used to find where components are added to a Builder.
+func callSite(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+}
+
+// atSite prefixes the message of a ComponentDefinitionError with site.
+func atSite(site string, err error) error {
+	if e, ok := err.(*ComponentDefinitionError); ok {
+		return &ComponentDefinitionError{
+			Component: e.Component,
+			message:   fmt.Sprintf("%s: %s", site, e.message),
+		}
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package acyclicloader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	loader, err := NewBuilder().
+		AddValue("Port", 80).
+		Add("Address", func(options struct{ Port int }) string {
+			return strings.Repeat("8", options.Port/40)
+		}).
+		Build()
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Address").(string) != "88" {
+		t.Error("expected '88'")
+	}
+
+	loader, err = NewBuilder().
+		AddValue("Port", 80).
+		Override("Port", func() int { return 120 }).
+		Add("Address", func(options struct{ Port int }) string {
+			return strings.Repeat("8", options.Port/40)
+		}).
+		Build()
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Address").(string) != "888" {
+		t.Error("expected '888'")
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	_, err := NewBuilder().
+		AddValue("Port", 80).
+		AddValue("Port", 80).
+		Add("Invalid", 42).
+		Override("Undefined", func() int { return 1 }).
+		Build()
+	t.Logf("got error as expected: '%s'", err)
+	e, ok := err.(*BuildError)
+	if !ok || len(e.Errors) != 3 {
+		t.Fatal("expected a BuildError with 3 errors")
+	}
+	if !strings.HasPrefix(e.Errors[0].Error(), "builder_test.go:") {
+		t.Error("expected error to include call site")
+	}
+
+	_, err = NewBuilder().
+		Add("A", func(options struct{ Port int }) int { return options.Port }).
+		Build()
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "builder_test.go:") {
+		t.Error("expected error to include call site")
+	}
+}
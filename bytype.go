@@ -0,0 +1,66 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// LoadByType loads the unique component with result type t, this is useful
+// when the caller only cares about "the *sql.DB" rather than its name.
+//
+// A TypeResolutionError is returned, if no component or more than one
+// component has result type t.
+func (a *AcyclicLoader) LoadByType(t reflect.Type) (interface{}, error) {
+	a.m.Lock()
+	name, err := a.componentOfType(t)
+	a.m.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return a.Load(name)
+}
+
+// componentOfType returns the name of the unique component with result type t,
+// must be called with a.m locked.
+func (a *AcyclicLoader) componentOfType(t reflect.Type) (string, error) {
+	var matches []string
+	for _, name := range sortedKeys(a.components) {
+		if c := a.components[name]; c.result == t && !c.keyed {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) != 1 {
+		return "", &TypeResolutionError{Type: t, Components: matches}
+	}
+	return matches[0], nil
+}
+
+// LoadInto loads the named component and stores it in the value dst points to,
+// this avoids type assertions that panic if the component has another type.
+//
+//   var db *sql.DB
+//   if err := loader.LoadInto("Database", &db); err != nil { ... }
+//
+// An error is returned without loading the component, if the component isn't
+// assignable to the value dst points to.
+func (a *AcyclicLoader) LoadInto(component string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("expected destination for '%s' to be a non-nil pointer, but found %T", component, dst)
+	}
+	a.m.Lock()
+	c, ok := a.components[component]
+	a.m.Unlock()
+	if ok && (c.result == nil || !c.result.AssignableTo(v.Elem().Type())) {
+		return fmt.Errorf(
+			"cannot load component '%s' which has type %v into %s",
+			component, c.result, v.Type().String(),
+		)
+	}
+	value, err := a.Load(component)
+	if err != nil {
+		return err
+	}
+	v.Elem().Set(valueOf(v.Elem().Type(), value))
+	return nil
+}
@@ -0,0 +1,57 @@
+package acyclicloader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadByType(t *testing.T) {
+	loader, err := New(Components{
+		"Port":    func() int { return 80 },
+		"Host":    func() string { return "localhost" },
+		"Address": func() string { return "localhost:80" },
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	port, err := loader.LoadByType(reflect.TypeOf(0))
+	if err != nil || port.(int) != 80 {
+		t.Error("expected 80, got: ", port, err)
+	}
+
+	_, err = loader.LoadByType(reflect.TypeOf(""))
+	t.Logf("got error as expected: '%s'", err)
+	if e, ok := err.(*TypeResolutionError); !ok || len(e.Components) != 2 {
+		t.Error("expected a TypeResolutionError listing 2 components")
+	}
+
+	_, err = loader.LoadByType(reflect.TypeOf(0.0))
+	t.Logf("got error as expected: '%s'", err)
+	if e, ok := err.(*TypeResolutionError); !ok || len(e.Components) != 0 {
+		t.Error("expected a TypeResolutionError listing no components")
+	}
+}
+
+func TestLoadInto(t *testing.T) {
+	loader := Components{
+		"English": func() *englishGreeter { return &englishGreeter{} },
+		"Port":    func() int { return 80 },
+	}.AsLoader()
+
+	var greeter testGreeter
+	if err := loader.LoadInto("English", &greeter); err != nil || greeter.Greet() != "hello" {
+		t.Error("expected 'hello', got: ", err)
+	}
+
+	var port string
+	err := loader.LoadInto("Port", &port)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for type mismatch")
+	}
+
+	err = loader.LoadInto("Prot", &port)
+	if _, ok := err.(*UndefinedComponentError); !ok {
+		t.Error("expected an UndefinedComponentError, got: ", err)
+	}
+}
@@ -0,0 +1,122 @@
+package acyclicloader
+
+import "reflect"
+
+// Child creates an AcyclicLoader from a set of components, which may depend on
+// components from a. Components not defined in the child loader are loaded from
+// a, sharing the cache of a.
+//
+// This allows for a shared loader with infrastructure components, such as
+// configuration, database and logger, with per-service child loaders on top.
+// Components defined in the child loader take precedence over components
+// with the same name in a.
+//
+// Values purged from a, such as by a.Refresh() or a.Shutdown(), are purged
+// from the child loader too, along with components depending on them, when the
+// child loader loads a component next.
+func (a *AcyclicLoader) Child(components Components, options ...Option) (*AcyclicLoader, error) {
+	return New(components, append([]Option{withParent(a)}, options...)...)
+}
+
+func withParent(parent *AcyclicLoader) Option {
+	return func(a *AcyclicLoader) {
+		a.parent = parent
+	}
+}
+
+// inherit returns a component loading the named component from a, for use in
+// a child loader.
+func (a *AcyclicLoader) inherit(name string) *component {
+	c := a.components[name]
+	var fn reflect.Value
+	if c.keyed {
+		fn = a.keyedLoadFunc(name, keyedLoadFuncType(c.result))
+	} else {
+		fn = a.loadFunc(name, c.result)
+	}
+	return &component{definition: &definition{
+		fn:               fn,
+		result:           c.result,
+		keyed:            c.keyed,
+		element:          c.element,
+		notGoroutineSafe: c.notGoroutineSafe,
+		serialize:        c.serialize,
+		tags:             c.tags,
+		inherited:        true,
+	}}
+}
+
+// loadInherited copies the cached value/err pair of c from the parent loader,
+// if c is inherited and already loaded by the parent, such that no goroutine
+// is needed to load it. Returns true, if c was loaded, and must be called with
+// a.m locked.
+func (a *AcyclicLoader) loadInherited(name string, c *component) bool {
+	if !c.inherited || c.keyed || a.parent == nil || c.loaded || c.loading {
+		return false
+	}
+	a.parent.m.Lock()
+	defer a.parent.m.Unlock()
+
+	p, ok := a.parent.components[name]
+	if !ok {
+		return false
+	}
+	a.parent.expire(p)
+	if !p.loaded {
+		return false
+	}
+	c.value = p.value
+	c.err = p.err
+	c.loaded = true
+	c.loading = true
+	c.loadedAt = p.loadedAt
+	a.c.Broadcast() // c may be awaited by a dependent loading it in another goroutine
+	return true
+}
+
+// inheritLoadedAt sets the time c was loaded to the time the parent loader
+// loaded it, after loading c from the parent, such that expireInherited() can
+// tell whether the parent has loaded it again. Must be called with a.m locked.
+func (a *AcyclicLoader) inheritLoadedAt(name string, c *component) {
+	a.parent.m.Lock()
+	defer a.parent.m.Unlock()
+
+	if p, ok := a.parent.components[name]; ok && p.loaded {
+		c.loadedAt = p.loadedAt
+	}
+}
+
+// expireInherited purges inherited components whose value has been purged or
+// loaded again by the parent loader, such as by Refresh() or Shutdown() on the
+// parent, along with loaded components depending on them. Purged values are
+// not closed, as they are owned by the parent. Must be called with a.m locked.
+func (a *AcyclicLoader) expireInherited() {
+	if a.parent == nil {
+		return
+	}
+	a.parent.m.Lock()
+	if a.parent.generation == a.parentGeneration {
+		a.parent.m.Unlock()
+		return
+	}
+	a.parentGeneration = a.parent.generation
+	stale := map[string]bool{}
+	for name, c := range a.components {
+		if !c.inherited || c.keyed || !c.loaded {
+			continue
+		}
+		p, ok := a.parent.components[name]
+		if !ok || p.transient {
+			continue
+		}
+		a.parent.expire(p)
+		if !p.loaded || !p.loadedAt.Equal(c.loadedAt) {
+			stale[name] = true
+		}
+	}
+	a.parent.m.Unlock()
+
+	if len(stale) > 0 {
+		a.purgeLocked(func(name string) bool { return stale[name] }, true)
+	}
+}
@@ -0,0 +1,130 @@
+package acyclicloader
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestChild(t *testing.T) {
+	count := 0
+	parent, _ := New(Components{
+		"Config": func() int {
+			count++
+			return 5
+		},
+		"Name": func() string { return "parent" },
+	})
+
+	child, err := parent.Child(Components{
+		"Name": func() string { return "child" },
+		"Service": func(options struct {
+			Config int
+			Name   string
+		}) string {
+			return options.Name
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if child.MustLoad("Service").(string) != "child" {
+		t.Error("expected 'child'")
+	}
+	if child.MustLoad("Config").(int) != 5 || parent.MustLoad("Config").(int) != 5 {
+		t.Error("expected 5")
+	}
+	if count != 1 {
+		t.Error("expected 'Config' to be loaded once and cached in the parent")
+	}
+	if parent.MustLoad("Name").(string) != "parent" {
+		t.Error("expected 'parent'")
+	}
+
+	_, err = parent.Child(Components{
+		"Service": func(options struct{ Config string }) string { return options.Config },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestChildCachedInherited(t *testing.T) {
+	parent, _ := New(Components{
+		"Config":   func() int { return 5 },
+		"Database": func(options struct{ Config int }) int { return options.Config },
+	})
+	parent.MustLoad("Database")
+
+	child, _ := parent.Child(Components{
+		"Service": func(options struct {
+			Config   int
+			Database int
+		}) int {
+			return options.Config + options.Database
+		},
+	})
+	recorder := child.Recorder()
+	if child.MustLoad("Service").(int) != 10 {
+		t.Error("expected 10")
+	}
+	if loaded := recorder.Components(); len(loaded) != 1 || loaded[0] != "Service" {
+		t.Error("expected components cached by the parent not to be loaded again, got: ", loaded)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { child.MustLoad("Service") }); allocs != 0 {
+		t.Error("expected loading a cached component not to allocate, got: ", allocs)
+	}
+}
+
+func TestChildParentRefresh(t *testing.T) {
+	var closed []string
+	count := 0
+	parent, _ := New(Components{
+		"Database": func() *testCloser {
+			count++
+			return &testCloser{name: fmt.Sprintf("Database%d", count), closed: &closed}
+		},
+	})
+
+	// One child loads 'Database' from the parent, the other copies it
+	loading, _ := parent.Child(Components{
+		"Service": func(options struct{ Database *testCloser }) string {
+			return options.Database.name
+		},
+	})
+	if loading.MustLoad("Service").(string) != "Database1" {
+		t.Error("expected 'Database1'")
+	}
+	copying, _ := parent.Child(Components{
+		"Service": func(options struct{ Database *testCloser }) string {
+			return options.Database.name
+		},
+	})
+	if copying.MustLoad("Service").(string) != "Database1" {
+		t.Error("expected 'Database1'")
+	}
+
+	if err := parent.Refresh("Database"); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.WaitIdle(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if loading.MustLoad("Service").(string) != "Database2" || copying.MustLoad("Service").(string) != "Database2" {
+		t.Error("expected children to use the refreshed 'Database2'")
+	}
+	if loading.MustLoad("Database").(*testCloser).name != "Database2" {
+		t.Error("expected inherited 'Database2'")
+	}
+
+	if err := parent.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if copying.MustLoad("Service").(string) != "Database3" {
+		t.Error("expected child to load 'Database3' after the parent was shut down")
+	}
+	if len(closed) != 2 {
+		t.Errorf("expected only the parent to close values, closed: %v", closed)
+	}
+}
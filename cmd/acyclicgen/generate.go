@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A component is a component found in the Components literal.
+type component struct {
+	name       string
+	method     string     // name of the generated accessor
+	field      string     // name of the generated field holding state
+	fn         types.Type // type of the function loading the component
+	result     types.Type // nil, if the function only returns an error
+	hasError   bool
+	options    types.Type // nil, if the function takes no options
+	dependency []dependency
+}
+
+// A dependency is a field in the options struct of a component.
+type dependency struct {
+	field     string
+	component string
+	optional  bool
+}
+
+// generate returns the source of a loader for the Components literal held by
+// the variable varName in the package in dir.
+func generate(dir, varName, typeName string) ([]byte, error) {
+	pkg, pkgName, components, order, err := load(dir, varName)
+	if err != nil {
+		return nil, err
+	}
+	return emit(pkg, pkgName, varName, typeName, components, order)
+}
+
+// load parses and type checks the package in dir, and returns the components
+// in the Components literal held by the variable varName, along with their
+// names in topological order.
+func load(dir, varName string) (*types.Package, string, map[string]*component, []string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, "", nil, nil, fmt.Errorf("expected exactly one package in %s, found %d", dir, len(pkgs))
+	}
+	var files []*ast.File
+	var pkgName string
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+	}
+
+	// Type check the package, errors are ignored as the package may reference
+	// a previously generated loader that is out of date.
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(pkgName, fset, files, info)
+
+	literal := findLiteral(files, varName)
+	if literal == nil {
+		return nil, "", nil, nil, fmt.Errorf("cannot find variable '%s' holding a composite literal", varName)
+	}
+	components, err := analyze(fset, info, literal)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	order, err := schedule(components)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	return pkg, pkgName, components, order, nil
+}
+
+// findLiteral returns the composite literal assigned to the package-level
+// variable varName, or nil if there is no such variable.
+func findLiteral(files []*ast.File, varName string) *ast.CompositeLit {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if name.Name != varName || i >= len(vs.Values) {
+						continue
+					}
+					literal, _ := vs.Values[i].(*ast.CompositeLit)
+					return literal
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// analyze returns the components in literal, indexed by name.
+func analyze(fset *token.FileSet, info *types.Info, literal *ast.CompositeLit) (map[string]*component, error) {
+	components := map[string]*component{}
+	methods := map[string]string{}
+	for i, elt := range literal.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected key-value pair", fset.Position(elt.Pos()))
+		}
+		key := info.Types[kv.Key]
+		if key.Value == nil || key.Value.Kind() != constant.String {
+			return nil, fmt.Errorf("%s: expected component name to be a constant string", fset.Position(kv.Key.Pos()))
+		}
+		name := constant.StringVal(key.Value)
+		sig, ok := info.TypeOf(kv.Value).(*types.Signature)
+		if !ok {
+			return nil, fmt.Errorf(
+				"%s: expected definition of '%s' to be a function, Annotate() is not supported",
+				fset.Position(kv.Value.Pos()), name,
+			)
+		}
+		c, err := analyzeComponent(name, sig)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", fset.Position(kv.Value.Pos()), err)
+		}
+		if other, ok := methods[c.method]; ok {
+			return nil, fmt.Errorf("components '%s' and '%s' both map to %s()", other, name, c.method)
+		}
+		methods[c.method] = name
+		c.field = fmt.Sprintf("c%d", i)
+		components[name] = c
+	}
+	return components, nil
+}
+
+// analyzeComponent returns the component defined by a function with signature
+// sig, or an error if it isn't supported.
+func analyzeComponent(name string, sig *types.Signature) (*component, error) {
+	c := &component{
+		name:   name,
+		method: "Load" + identifier(name),
+		fn:     sig,
+	}
+	results := sig.Results()
+	switch {
+	case results.Len() == 1 && isError(results.At(0).Type()):
+		c.hasError = true
+	case results.Len() == 1:
+		c.result = results.At(0).Type()
+	case results.Len() == 2 && isError(results.At(1).Type()):
+		c.result = results.At(0).Type()
+		c.hasError = true
+	case results.Len() != 0:
+		return nil, fmt.Errorf("unsupported results from '%s': %s", name, results.String())
+	}
+
+	params := sig.Params()
+	if params.Len() == 0 {
+		return c, nil
+	}
+	if params.Len() != 1 || sig.Variadic() {
+		return nil, fmt.Errorf("expected no more than 1 input parameter for '%s'", name)
+	}
+	c.options = params.At(0).Type()
+	t := c.options
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	s, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("expected input parameter for '%s' to be a struct or pointer to a struct", name)
+	}
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		if field.Embedded() || !field.Exported() {
+			return nil, fmt.Errorf("unsupported field '%s' in options for '%s'", field.Name(), name)
+		}
+		dep := dependency{field: field.Name(), component: field.Name()}
+		parts := strings.Split(reflect.StructTag(s.Tag(i)).Get("component"), ",")
+		if n := strings.TrimSpace(parts[0]); n != "" {
+			dep.component = n
+		}
+		for _, flag := range parts[1:] {
+			if strings.TrimSpace(flag) != "optional" {
+				return nil, fmt.Errorf("unsupported flag '%s' on field '%s' in options for '%s'", flag, field.Name(), name)
+			}
+			dep.optional = true
+		}
+		c.dependency = append(c.dependency, dep)
+	}
+	return c, nil
+}
+
+// schedule checks dependencies and returns the names of the components in
+// topological order, such that dependencies come before dependents.
+func schedule(components map[string]*component) ([]string, error) {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var order []string
+	state := map[string]int{} // 1: visiting, 2: visited
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("dependency cycle detected: '%s' -> '%s'", strings.Join(path, "' -> '"), name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		c := components[name]
+		for i, dep := range c.dependency {
+			target, ok := components[dep.component]
+			if !ok && dep.optional {
+				continue
+			}
+			if !ok {
+				return fmt.Errorf("'%s' depends on undefined component '%s'", name, dep.component)
+			}
+			field := fieldType(c, i)
+			if target.result == nil || !types.AssignableTo(target.result, field) {
+				return fmt.Errorf(
+					"'%s' depends on component '%s' which has type %v, but '%s' expects %s",
+					name, dep.component, target.result, name, field,
+				)
+			}
+			if err := visit(dep.component, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// emit returns the formatted source of the generated loader.
+func emit(pkg *types.Package, pkgName, varName, typeName string, components map[string]*component, order []string) ([]byte, error) {
+	imports := map[string]string{"sync": "sync"}
+	qualifier := func(p *types.Package) string {
+		if pkg != nil && p.Path() == pkg.Path() {
+			return ""
+		}
+		imports[p.Path()] = p.Name()
+		return p.Name()
+	}
+	typeString := func(t types.Type) string {
+		return types.TypeString(t, qualifier)
+	}
+
+	var body bytes.Buffer
+	w := func(format string, args ...interface{}) {
+		fmt.Fprintf(&body, format, args...)
+	}
+
+	w("// %s loads the components defined in %s without reflection.\n", typeName, varName)
+	w("type %s struct {\n", typeName)
+	w("components acyclicloader.Components\n")
+	for _, name := range order {
+		c := components[name]
+		w("%s struct { // %q\n once sync.Once\n", c.field, name)
+		if c.result != nil {
+			w("value %s\n", typeString(c.result))
+		}
+		w("err error\n}\n")
+	}
+	w("}\n\n")
+
+	w("// New%s returns a %s loading components using the functions from\n", typeName, typeName)
+	w("// components, which must have the same types as in %s.\n", varName)
+	w("func New%s(components acyclicloader.Components) *%s {\n", typeName, typeName)
+	w("return &%s{components: components}\n}\n\n", typeName)
+
+	for _, name := range order {
+		c := components[name]
+		returns := "error"
+		zero := "err"
+		if c.result != nil {
+			returns = fmt.Sprintf("(%s, error)", typeString(c.result))
+			zero = fmt.Sprintf("l.%s.value, err", c.field)
+		}
+		w("// %s loads the %q component.\n", c.method, name)
+		w("func (l *%s) %s() %s {\n", typeName, c.method, returns)
+		w("l.%s.once.Do(func() {\n", c.field)
+		if c.options != nil {
+			options := c.options
+			ptr := false
+			if p, ok := options.(*types.Pointer); ok {
+				options, ptr = p.Elem(), true
+			}
+			if ptr {
+				w("options := &%s{}\n", typeString(options))
+			} else {
+				w("var options %s\n", typeString(options))
+			}
+			for _, dep := range c.dependency {
+				target, ok := components[dep.component]
+				if !ok {
+					continue // optional dependency left as zero value
+				}
+				w("if value, err := l.%s(); err != nil {\n", target.method)
+				w("l.%s.err = fmt.Errorf(\"failed to load dependency '%%s' of '%%s': %%w\", %q, %q, err)\n", c.field, dep.component, name)
+				w("return\n} else {\noptions.%s = value\n}\n", dep.field)
+			}
+		}
+		call := "fn()"
+		if c.options != nil {
+			call = "fn(options)"
+		}
+		w("fn := l.components[%q].(%s)\n", name, typeString(c.fn))
+		switch {
+		case c.result != nil && c.hasError:
+			w("l.%s.value, l.%s.err = %s\n", c.field, c.field, call)
+		case c.result != nil:
+			w("l.%s.value = %s\n", c.field, call)
+		case c.hasError:
+			w("l.%s.err = %s\n", c.field, call)
+		default:
+			w("%s\n", call)
+		}
+		w("})\n")
+		w("err := l.%s.err\nreturn %s\n}\n\n", c.field, zero)
+	}
+
+	w("// LoadAll loads all components in topological order, returning the first\n")
+	w("// error encountered.\n")
+	w("func (l *%s) LoadAll() error {\n", typeName)
+	for _, name := range order {
+		c := components[name]
+		if c.result != nil {
+			w("if _, err := l.%s(); err != nil {\nreturn err\n}\n", c.method)
+		} else {
+			w("if err := l.%s(); err != nil {\nreturn err\n}\n", c.method)
+		}
+	}
+	w("return nil\n}\n")
+
+	if strings.Contains(body.String(), "fmt.Errorf") {
+		imports["fmt"] = "fmt"
+	}
+	imports["github.com/jonasfj/go-acyclicloader"] = "acyclicloader"
+	return source(pkgName, imports, body.Bytes())
+}
+
+// source returns the formatted source of a generated file in the package
+// pkgName, importing imports, which maps import paths to package names.
+func source(pkgName string, imports map[string]string, body []byte) ([]byte, error) {
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by acyclicgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	for _, path := range paths {
+		if name := imports[path]; name != pathBase(path) {
+			fmt.Fprintf(&src, "%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&src, "%q\n", path)
+		}
+	}
+	fmt.Fprintf(&src, ")\n\n")
+	src.Write(body)
+	return format.Source(src.Bytes())
+}
+
+// fieldType returns the type of the i'th field in the options of c.
+func fieldType(c *component, i int) types.Type {
+	t := c.options
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	return t.Underlying().(*types.Struct).Field(i).Type()
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// identifier returns name as an exported Go identifier, such that "read-replica"
+// becomes "ReadReplica".
+func identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func pathBase(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("testdata/basic", "components", "Loader")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	for _, want := range []string{
+		"// Code generated by acyclicgen. DO NOT EDIT.",
+		"package basic",
+		"func NewLoader(components acyclicloader.Components) *Loader {",
+		"func (l *Loader) LoadConfig() (Config, error) {",
+		"func (l *Loader) LoadHandler() (http.Handler, error) {",
+		"func (l *Loader) LoadServer() (*http.Server, error) {",
+		"func (l *Loader) LoadReadReplica() error {",
+		"options.C = value",
+		"func (l *Loader) LoadAll() error {",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected generated source to contain %q, got:\n%s", want, src)
+		}
+	}
+	if strings.Contains(string(src), `"reflect"`) {
+		t.Error("expected generated source not to use reflection")
+	}
+	// Optional dependency on an undefined component is left as zero value
+	if strings.Contains(string(src), "Loadlogger") || strings.Contains(string(src), "options.Logger") {
+		t.Error("expected undefined optional dependency to be skipped")
+	}
+	// Dependencies are scheduled before dependents
+	if strings.Index(string(src), "LoadConfig(); err") > strings.Index(string(src), "LoadServer(); err") {
+		t.Error("expected Config to be loaded before Server in LoadAll()")
+	}
+}
+
+func TestGenerateCycle(t *testing.T) {
+	_, err := generate("testdata/cycle", "components", "Loader")
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatal("expected cycle error, got: ", err)
+	}
+}
+
+func TestGenerateMissingVariable(t *testing.T) {
+	_, err := generate("testdata/basic", "missing", "Loader")
+	if err == nil {
+		t.Fatal("expected error for missing variable")
+	}
+}
+
+func TestGenerateWire(t *testing.T) {
+	src, err := generateWire("testdata/basic", "components", "ProviderSet")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	for _, want := range []string{
+		`"github.com/google/wire"`,
+		"var ProviderSet = wire.NewSet(",
+		"func provideConfig() (Config, error) {",
+		"func provideServer(depConfig Config, depHandler http.Handler) (*http.Server, error) {",
+		"options.Handler = depHandler",
+		"return fn(), nil",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected generated source to contain %q, got:\n%s", want, src)
+		}
+	}
+	// Components without a result can't be provided
+	if strings.Contains(string(src), "provideReadReplica") {
+		t.Error("expected 'read-replica' not to be provided")
+	}
+}
@@ -0,0 +1,76 @@
+// Command acyclicgen generates a loader without reflection from a Components
+// definition.
+//
+// The generated loader has a typed accessor for each component, such as
+// LoadServer() (*http.Server, error), which loads dependencies in a static
+// topological order and calls the function from the Components definition
+// without reflection or interface{} casts. This is useful for services where
+// startup time is critical.
+//
+// The Components definition must be a package-level variable holding a
+// composite literal, such as:
+//
+//   var components = acyclicloader.Components{
+//       "Port": func() int { return 80 },
+//       "Server": func(options struct{ Port int }) *http.Server { ... },
+//   }
+//
+// and the generated loader is created with NewLoader(components). Usage:
+//
+//   //go:generate acyclicgen -var components -type Loader -o loader_gen.go
+//
+// With -wire the command instead generates a google/wire ProviderSet, with a
+// provider for each component, which allows experimenting with compile-time
+// dependency injection while keeping the Components definition:
+//
+//   //go:generate acyclicgen -var components -wire ProviderSet -o wire_gen.go
+//
+// As wire wires dependencies by type, components must have distinct types, and
+// components that only return an error are not provided.
+//
+// Only plain dependencies, renamed dependencies and optional dependencies are
+// supported, definitions using Annotate(), embedded structs, lazy, group or
+// other special fields are reported as errors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	varName := flag.String("var", "components", "name of variable holding the Components literal")
+	typeName := flag.String("type", "Loader", "name of the generated loader type")
+	wireSet := flag.String("wire", "", "generate a wire ProviderSet with this name, instead of a loader")
+	output := flag.String("o", "", "output file, defaults to stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: acyclicgen [flags] [package directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	generator := generate
+	name := *typeName
+	if *wireSet != "" {
+		generator, name = generateWire, *wireSet
+	}
+	src, err := generator(dir, *varName, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "acyclicgen: %s\n", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "acyclicgen: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,32 @@
+package basic
+
+import (
+	"net/http"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+type Config struct {
+	Addr string
+}
+
+var components = acyclicloader.Components{
+	"Config": func() (Config, error) {
+		return Config{Addr: ":8080"}, nil
+	},
+	"Handler": func() http.Handler {
+		return http.NotFoundHandler()
+	},
+	"Server": func(options struct {
+		Config  Config
+		Handler http.Handler
+		Logger  interface{} `component:"logger,optional"`
+	}) (*http.Server, error) {
+		return &http.Server{Addr: options.Config.Addr, Handler: options.Handler}, nil
+	},
+	"read-replica": func(options *struct {
+		C Config `component:"Config"`
+	}) error {
+		return nil
+	},
+}
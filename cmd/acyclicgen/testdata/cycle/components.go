@@ -0,0 +1,8 @@
+package cycle
+
+import "github.com/jonasfj/go-acyclicloader"
+
+var components = acyclicloader.Components{
+	"A": func(options struct{ B int }) int { return options.B },
+	"B": func(options struct{ A int }) int { return options.A },
+}
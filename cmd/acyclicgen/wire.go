@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// generateWire returns the source of a google/wire ProviderSet named setName,
+// with a provider for each component in the Components literal held by the
+// variable varName in the package in dir.
+func generateWire(dir, varName, setName string) ([]byte, error) {
+	pkg, pkgName, components, order, err := load(dir, varName)
+	if err != nil {
+		return nil, err
+	}
+	return emitWire(pkg, pkgName, varName, setName, components, order)
+}
+
+// emitWire returns the formatted source of the ProviderSet. As wire wires
+// dependencies by type, components must have distinct types, and components
+// that only return an error are skipped, as they can't be provided.
+func emitWire(pkg *types.Package, pkgName, varName, setName string, components map[string]*component, order []string) ([]byte, error) {
+	imports := map[string]string{}
+	qualifier := func(p *types.Package) string {
+		if pkg != nil && p.Path() == pkg.Path() {
+			return ""
+		}
+		imports[p.Path()] = p.Name()
+		return p.Name()
+	}
+	typeString := func(t types.Type) string {
+		return types.TypeString(t, qualifier)
+	}
+
+	// Wire can't distinguish components with identical types
+	var providers []string
+	for i, name := range order {
+		c := components[name]
+		if c.result == nil {
+			continue
+		}
+		for _, other := range order[:i] {
+			if o := components[other]; o.result != nil && types.Identical(o.result, c.result) {
+				return nil, fmt.Errorf(
+					"components '%s' and '%s' both have type %s, which wire cannot distinguish",
+					other, name, typeString(c.result),
+				)
+			}
+		}
+		providers = append(providers, "provide"+identifier(name))
+	}
+
+	var body bytes.Buffer
+	w := func(format string, args ...interface{}) {
+		fmt.Fprintf(&body, format, args...)
+	}
+
+	w("// %s provides the components defined in %s to wire.\n", setName, varName)
+	w("var %s = wire.NewSet(\n%s,\n)\n\n", setName, strings.Join(providers, ",\n"))
+
+	for _, name := range order {
+		c := components[name]
+		if c.result == nil {
+			continue
+		}
+		var params []string
+		for _, dep := range c.dependency {
+			if target, ok := components[dep.component]; ok {
+				params = append(params, fmt.Sprintf("%s %s", paramName(dep.field), typeString(target.result)))
+			}
+		}
+		w("// provide%s provides the %q component.\n", identifier(name), name)
+		w("func provide%s(%s) (%s, error) {\n", identifier(name), strings.Join(params, ", "), typeString(c.result))
+		call := "fn()"
+		if c.options != nil {
+			call = "fn(options)"
+			options := c.options
+			if p, ok := options.(*types.Pointer); ok {
+				w("options := &%s{}\n", typeString(p.Elem()))
+			} else {
+				w("var options %s\n", typeString(options))
+			}
+			for _, dep := range c.dependency {
+				if _, ok := components[dep.component]; ok {
+					w("options.%s = %s\n", dep.field, paramName(dep.field))
+				}
+			}
+		}
+		w("fn := %s[%q].(%s)\n", varName, name, typeString(c.fn))
+		if c.hasError {
+			w("return %s\n}\n\n", call)
+		} else {
+			w("return %s, nil\n}\n\n", call)
+		}
+	}
+
+	imports["github.com/google/wire"] = "wire"
+	return source(pkgName, imports, body.Bytes())
+}
+
+// paramName returns an unexported parameter name for the options field, which
+// doesn't collide with the variables used in generated providers.
+func paramName(field string) string {
+	return "dep" + field
+}
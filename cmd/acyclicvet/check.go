@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A diagnostic is a problem found in a Components literal.
+type diagnostic struct {
+	pos     token.Pos
+	message string
+}
+
+// A definition is a component in a Components literal.
+type definition struct {
+	pos token.Pos
+	sig *types.Signature // nil, if the definition can't be checked statically
+	// result is the type of the component, nil if it only returns an error
+	result       types.Type
+	dependencies []dependency
+}
+
+// A dependency is a field in the options struct of a definition.
+type dependency struct {
+	pos       token.Pos
+	field     *types.Var
+	component string
+	optional  bool
+	lazy      bool
+}
+
+// check returns diagnostics for all Components literals in files.
+func check(files []*ast.File, info *types.Info) []diagnostic {
+	var diagnostics []diagnostic
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if literal, ok := n.(*ast.CompositeLit); ok && isComponents(info.TypeOf(literal)) {
+				diagnostics = append(diagnostics, checkLiteral(literal, info)...)
+			}
+			return true
+		})
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].pos < diagnostics[j].pos
+	})
+	return diagnostics
+}
+
+// isComponents returns true, if t is acyclicloader.Components.
+func isComponents(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Components" && obj.Pkg() != nil && obj.Pkg().Name() == "acyclicloader"
+}
+
+// checkLiteral returns diagnostics for a Components literal.
+func checkLiteral(literal *ast.CompositeLit, info *types.Info) []diagnostic {
+	var diagnostics []diagnostic
+	report := func(pos token.Pos, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, diagnostic{pos: pos, message: fmt.Sprintf(format, args...)})
+	}
+
+	definitions := map[string]*definition{}
+	var names []string
+	for _, elt := range literal.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key := info.Types[kv.Key]
+		if key.Value == nil || key.Value.Kind() != constant.String {
+			continue // names computed at runtime can't be checked
+		}
+		name := constant.StringVal(key.Value)
+		d := &definition{pos: kv.Value.Pos()}
+		if sig, ok := info.TypeOf(kv.Value).(*types.Signature); ok {
+			d.sig = sig
+			d.result, d.dependencies = analyze(sig)
+		}
+		definitions[name] = d
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d := definitions[name]
+		for _, dep := range d.dependencies {
+			target, ok := definitions[dep.component]
+			if !ok {
+				if !dep.optional {
+					report(dep.pos, "'%s' depends on undefined component '%s'", name, dep.component)
+				}
+				continue
+			}
+			if target.sig == nil || dep.lazy {
+				continue
+			}
+			if target.result == nil || !types.AssignableTo(target.result, dep.field.Type()) {
+				report(dep.pos,
+					"'%s' depends on component '%s' which has type %v, but '%s' expects %s",
+					name, dep.component, target.result, name, dep.field.Type(),
+				)
+			}
+		}
+	}
+
+	// Report each cycle once, at the component first in sorted order
+	visited := map[string]bool{}
+	var visit func(name string, path []string) bool
+	visit = func(name string, path []string) bool {
+		for i, n := range path {
+			if n == name {
+				cycle := append(path[i:len(path):len(path)], name)
+				report(definitions[path[i]].pos, "dependency cycle detected: '%s'", strings.Join(cycle, "' -> '"))
+				return true
+			}
+		}
+		if visited[name] {
+			return false
+		}
+		visited[name] = true
+		for _, dep := range definitions[name].dependencies {
+			if _, ok := definitions[dep.component]; ok && !dep.lazy {
+				if visit(dep.component, append(path, name)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, name := range names {
+		visit(name, nil)
+	}
+	return diagnostics
+}
+
+// analyze returns the result type and dependencies of a component defined by
+// a function with signature sig. Fields that can't be checked statically, such
+// as group fields, are skipped.
+func analyze(sig *types.Signature) (types.Type, []dependency) {
+	var result types.Type
+	results := sig.Results()
+	if results.Len() > 0 && !isError(results.At(0).Type()) {
+		result = results.At(0).Type()
+	}
+	if sig.Params().Len() != 1 {
+		return result, nil
+	}
+	t := sig.Params().At(0).Type()
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	s, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return result, nil
+	}
+	var dependencies []dependency
+	var walk func(s *types.Struct)
+	walk = func(s *types.Struct) {
+		for i := 0; i < s.NumFields(); i++ {
+			field := s.Field(i)
+			tag, tagged := reflect.StructTag(s.Tag(i)).Lookup("component")
+			if embedded, ok := field.Type().Underlying().(*types.Struct); ok && field.Embedded() && !tagged {
+				walk(embedded)
+				continue
+			}
+			if isSpecial(field.Type()) {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			dep := dependency{pos: field.Pos(), field: field, component: strings.TrimSpace(parts[0])}
+			if dep.component == "" {
+				dep.component = field.Name()
+			}
+			skip := false
+			for _, flag := range parts[1:] {
+				switch strings.TrimSpace(flag) {
+				case "optional":
+					dep.optional = true
+				case "lazy":
+					dep.lazy = true
+				default:
+					skip = true // group fields and the like aren't checked
+				}
+			}
+			if !skip {
+				dependencies = append(dependencies, dep)
+			}
+		}
+	}
+	walk(s)
+	return result, dependencies
+}
+
+// isSpecial returns true, if t is acyclicloader.Info or context.Context which
+// are not dependencies.
+func isSpecial(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	obj := named.Obj()
+	return (obj.Name() == "Info" && obj.Pkg().Name() == "acyclicloader") ||
+		(obj.Name() == "Context" && obj.Pkg().Path() == "context")
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
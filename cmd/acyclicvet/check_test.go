@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	_, diagnostics, err := checkDir("testdata/bad")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	want := []string{
+		"'Address' depends on component 'Port' which has type int, but 'Address' expects string",
+		"'Server' depends on undefined component 'Database'",
+		"dependency cycle detected: 'A' -> 'B' -> 'A'",
+	}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %v", len(want), diagnostics)
+	}
+	for i, d := range diagnostics {
+		if !strings.Contains(d.message, want[i]) {
+			t.Errorf("expected %q, got %q", want[i], d.message)
+		}
+	}
+}
+
+func TestCheckValid(t *testing.T) {
+	_, diagnostics, err := checkDir("testdata/good")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diagnostics)
+	}
+}
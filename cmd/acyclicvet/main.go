@@ -0,0 +1,253 @@
+// Command acyclicvet statically checks acyclicloader.Components literals,
+// reporting undefined dependencies, dependencies with mismatched types and
+// dependency cycles at build time, rather than when New() is called.
+//
+// The command implements the protocol used by go vet, so it can be used as:
+//
+//   go vet -vettool=$(which acyclicvet) ./...
+//
+// It can also be given package directories to check directly:
+//
+//   acyclicvet ./server ./worker
+//
+// Only components defined by functions in the literal itself are checked,
+// definitions wrapped with Annotate() and values computed at runtime are
+// skipped. Dependencies provided by Merge(), Child() or similar must be marked
+// optional, or will be reported as undefined.
+//
+// To avoid depending on golang.org/x/tools, this is not an analysis.Analyzer,
+// but a standalone command speaking the same protocol as unitchecker.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// jsonOutput is set when go vet asks for diagnostics in JSON.
+var jsonOutput bool
+
+func main() {
+	version := flag.String("V", "", "print version and exit")
+	flags := flag.Bool("flags", false, "print flags as JSON and exit")
+	flag.BoolVar(&jsonOutput, "json", false, "emit diagnostics as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: acyclicvet [package directory...]\n")
+		fmt.Fprintf(os.Stderr, "   or: go vet -vettool=$(which acyclicvet) [packages]\n")
+	}
+	flag.Parse()
+
+	switch {
+	case *version != "":
+		printVersion()
+		return
+	case *flags:
+		fmt.Println("[]") // no analyzer flags
+		return
+	case flag.NArg() == 1 && strings.HasSuffix(flag.Arg(0), ".cfg"):
+		os.Exit(runVet(flag.Arg(0)))
+	}
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	exit := 0
+	for _, dir := range dirs {
+		fset, diagnostics, err := checkDir(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "acyclicvet: %s\n", err)
+			os.Exit(1)
+		}
+		printDiagnostics(fset, diagnostics)
+		if len(diagnostics) > 0 {
+			exit = 1
+		}
+	}
+	os.Exit(exit)
+}
+
+// printVersion prints the version in the format go vet expects from -V=full,
+// using a hash of the executable as build ID, such that results are recomputed
+// when the command changes.
+func printVersion() {
+	progname := filepath.Base(os.Args[0])
+	f, err := os.Open(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "acyclicvet: %s\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		fmt.Fprintf(os.Stderr, "acyclicvet: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s version devel comments-go-here buildID=%02x\n", progname, h.Sum(nil))
+}
+
+// vetConfig is the configuration go vet passes to a vet tool for each package.
+type vetConfig struct {
+	ID                        string
+	Compiler                  string
+	Dir                       string
+	ImportPath                string
+	GoFiles                   []string
+	ImportMap                 map[string]string
+	PackageFile               map[string]string
+	VetxOnly                  bool
+	VetxOutput                string
+	SucceedOnTypecheckFailure bool
+}
+
+// runVet checks the package described by the config file given by go vet, and
+// returns the exit code.
+func runVet(filename string) int {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "acyclicvet: %s\n", err)
+		return 1
+	}
+	var cfg vetConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "acyclicvet: cannot decode %s: %s\n", filename, err)
+		return 1
+	}
+	// go vet expects facts to be written, even though there are none
+	if cfg.VetxOutput != "" {
+		if err := ioutil.WriteFile(cfg.VetxOutput, nil, 0666); err != nil {
+			fmt.Fprintf(os.Stderr, "acyclicvet: %s\n", err)
+			return 1
+		}
+	}
+	if cfg.VetxOnly {
+		return 0
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range cfg.GoFiles {
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			if cfg.SucceedOnTypecheckFailure {
+				return 0
+			}
+			fmt.Fprintf(os.Stderr, "acyclicvet: %s\n", err)
+			return 1
+		}
+		files = append(files, file)
+	}
+	compilerImporter := importer.ForCompiler(fset, cfg.Compiler, func(path string) (io.ReadCloser, error) {
+		file, ok := cfg.PackageFile[path]
+		if !ok {
+			return nil, fmt.Errorf("no package file for %q", path)
+		}
+		return os.Open(file)
+	})
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if mapped, ok := cfg.ImportMap[path]; ok {
+				path = mapped
+			}
+			return compilerImporter.Import(path)
+		}),
+	}
+	info := newInfo()
+	if _, err := conf.Check(cfg.ImportPath, fset, files, info); err != nil {
+		if cfg.SucceedOnTypecheckFailure {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "acyclicvet: %s\n", err)
+		return 1
+	}
+
+	diagnostics := check(files, info)
+	if jsonOutput {
+		return printJSON(cfg.ID, fset, diagnostics)
+	}
+	printDiagnostics(fset, diagnostics)
+	if len(diagnostics) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printJSON prints diagnostics in the JSON tree format used by unitchecker,
+// and returns the exit code.
+func printJSON(id string, fset *token.FileSet, diagnostics []diagnostic) int {
+	type jsonDiagnostic struct {
+		Posn    string `json:"posn"`
+		Message string `json:"message"`
+	}
+	tree := map[string]map[string][]jsonDiagnostic{}
+	if len(diagnostics) > 0 {
+		var list []jsonDiagnostic
+		for _, d := range diagnostics {
+			list = append(list, jsonDiagnostic{Posn: fset.Position(d.pos).String(), Message: d.message})
+		}
+		tree[id] = map[string][]jsonDiagnostic{"acyclicvet": list}
+	}
+	data, err := json.MarshalIndent(tree, "", "\t")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "acyclicvet: %s\n", err)
+		return 1
+	}
+	os.Stdout.Write(append(data, '\n'))
+	return 0
+}
+
+// checkDir parses and type checks the non-test files of the package in dir,
+// and returns diagnostics for the Components literals found.
+func checkDir(dir string) (*token.FileSet, []diagnostic, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var diagnostics []diagnostic
+	for name, pkg := range pkgs {
+		var files []*ast.File
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		info := newInfo()
+		if _, err := conf.Check(name, fset, files, info); err != nil {
+			return nil, nil, err
+		}
+		diagnostics = append(diagnostics, check(files, info)...)
+	}
+	return fset, diagnostics, nil
+}
+
+func newInfo() *types.Info {
+	return &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+}
+
+func printDiagnostics(fset *token.FileSet, diagnostics []diagnostic) {
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", fset.Position(d.pos), d.message)
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
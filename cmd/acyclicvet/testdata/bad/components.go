@@ -0,0 +1,22 @@
+package bad
+
+import "github.com/jonasfj/go-acyclicloader"
+
+var components = acyclicloader.Components{
+	"Port": func() int { return 80 },
+	"Host": func() (string, error) { return "localhost", nil },
+	"Address": func(options struct {
+		Host string
+		Port string // mismatch
+	}) string {
+		return options.Host + ":" + options.Port
+	},
+	"Server": func(options struct {
+		Database interface{} // undefined
+		Cache    interface{} `component:",optional"`
+	}) error {
+		return nil
+	},
+	"A": func(options struct{ B int }) int { return options.B },
+	"B": func(options struct{ A int }) int { return options.A },
+}
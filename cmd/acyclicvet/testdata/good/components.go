@@ -0,0 +1,22 @@
+package good
+
+import (
+	"context"
+	"time"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+var components = acyclicloader.Components{
+	"Timeout": func() time.Duration { return time.Second },
+	"Client": func(options *struct {
+		Context context.Context
+		Info    acyclicloader.Info
+		Timeout time.Duration
+		Cache   interface{}                   `component:",optional"`
+		Loader  func() (time.Duration, error) `component:"Timeout,lazy"`
+	}) (interface{}, error) {
+		return nil, nil
+	},
+	"Annotated": acyclicloader.Annotate(func(key string) int { return 0 }, acyclicloader.Keyed()),
+}
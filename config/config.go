@@ -0,0 +1,130 @@
+// Package config builds components from a configuration file, such that
+// configuration values participate in the dependency graph and can be
+// overwritten in tests like any other component.
+//
+// A Schema maps configuration keys to default values, the type of each
+// default value is the type of the component created for the key. Values are
+// validated against the schema when the components are created, and the types
+// of dependents are checked against the schema when the loader is created.
+//
+//   components, err := config.Load("config.json", config.Schema{
+//       "Port":    80,
+//       "Host":    "localhost",
+//       "Timeout": 30 * time.Second,
+//   })
+//   if err != nil { ... }
+//   components, err = components.Merge(app.Components)
+//
+// Only JSON files are supported, YAML and other formats can be decoded into a
+// map and passed to FromMap().
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+var typeOfDuration = reflect.TypeOf(time.Duration(0))
+
+// A Schema maps configuration keys to default values, keys are used as
+// component names and default values determine the component types.
+type Schema map[string]interface{}
+
+// Load reads the configuration file at path and returns components for the
+// keys in schema. Only files with a .json extension are supported.
+func Load(path string, schema Schema) (acyclicloader.Components, error) {
+	if ext := filepath.Ext(path); ext != ".json" {
+		return nil, fmt.Errorf("unsupported configuration file format %q in %s", ext, path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	components, err := FromJSON(data, schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return components, nil
+}
+
+// FromJSON returns components for the keys in schema, with values from the
+// JSON object in data.
+func FromJSON(data []byte, schema Schema) (acyclicloader.Components, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return FromMap(values, schema)
+}
+
+// FromMap returns components for the keys in schema, with values from values
+// converted to the type of the default value. Keys missing from values use
+// the default value, and keys in values that aren't in schema are reported as
+// an error, as they are most likely typos.
+//
+// Values are converted by encoding them as JSON and decoding them into the
+// type of the default value, such that nested objects can be decoded into
+// structs. A time.Duration may also be given as a string, such as "1m30s".
+func FromMap(values map[string]interface{}, schema Schema) (acyclicloader.Components, error) {
+	var unknown []string
+	for key := range values {
+		if _, ok := schema[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown configuration keys '%s'", strings.Join(unknown, "', '"))
+	}
+
+	components := make(acyclicloader.Components, len(schema))
+	for key, def := range schema {
+		if def == nil {
+			return nil, fmt.Errorf("cannot determine type of configuration key '%s' from nil", key)
+		}
+		t := reflect.TypeOf(def)
+		value := reflect.ValueOf(def)
+		if raw, ok := values[key]; ok {
+			v, err := convert(raw, t)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for configuration key '%s': %w", key, err)
+			}
+			value = v
+		}
+		components[key] = valueFunc(value)
+	}
+	return components, nil
+}
+
+// convert returns raw converted to type t.
+func convert(raw interface{}, t reflect.Type) (reflect.Value, error) {
+	if s, ok := raw.(string); ok && t == typeOfDuration {
+		d, err := time.ParseDuration(s)
+		return reflect.ValueOf(d), err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return v.Elem(), nil
+}
+
+// valueFunc returns a function on the form func() T returning value.
+func valueFunc(value reflect.Value) interface{} {
+	ft := reflect.FuncOf(nil, []reflect.Type{value.Type()}, false)
+	return reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{value}
+	}).Interface()
+}
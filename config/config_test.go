@@ -0,0 +1,103 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+type database struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+var schema = Schema{
+	"Port":     80,
+	"Debug":    false,
+	"Timeout":  30 * time.Second,
+	"Database": database{Host: "localhost", Port: 5432},
+}
+
+func TestFromJSON(t *testing.T) {
+	components, err := FromJSON([]byte(`{
+		"Port": 443,
+		"Timeout": "1m",
+		"Database": {"host": "db.example.com", "port": 5433}
+	}`), schema)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	components["Address"] = func(options struct {
+		Database database
+		Port     int
+	}) string {
+		return options.Database.Host
+	}
+	loader := components.AsLoader()
+	if loader.MustLoad("Port").(int) != 443 {
+		t.Error("expected 443")
+	}
+	if loader.MustLoad("Debug").(bool) {
+		t.Error("expected default value false")
+	}
+	if loader.MustLoad("Timeout").(time.Duration) != time.Minute {
+		t.Error("expected 1m")
+	}
+	if loader.MustLoad("Address").(string) != "db.example.com" {
+		t.Error("expected 'db.example.com'")
+	}
+
+	// Dependents are type checked against the schema
+	components["Address"] = func(options struct{ Port string }) string {
+		return options.Port
+	}
+	_, err = acyclicloader.New(components)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestFromJSONInvalid(t *testing.T) {
+	_, err := FromJSON([]byte(`{"Port": "https"}`), schema)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for invalid value")
+	}
+
+	_, err = FromJSON([]byte(`{"Prot": 443}`), schema)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for unknown key")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acyclicloader-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"Debug": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	components, err := Load(path, schema)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if !components.MustLoad("Debug").(bool) {
+		t.Error("expected true")
+	}
+
+	_, err = Load(filepath.Join(dir, "config.yaml"), schema)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for unsupported format")
+	}
+}
@@ -0,0 +1,171 @@
+package acyclicloader
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A componentStatus describes the state of a component for DebugHandler().
+type componentStatus struct {
+	Name         string        `json:"name"`
+	Type         string        `json:"type,omitempty"`
+	State        string        `json:"state"`
+	Dependencies []string      `json:"dependencies"`
+	Tags         []string      `json:"tags,omitempty"`
+	LoadedAt     time.Time     `json:"loadedAt,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	Error        string        `json:"error,omitempty"`
+	Instances    int           `json:"instances,omitempty"`
+}
+
+// DebugHandler returns an http.Handler serving the state of all components,
+// with load durations and errors, as HTML or as JSON if requested with
+// ?format=json or an Accept header of application/json.
+//
+//   http.Handle("/debug/components/", http.StripPrefix(
+//       "/debug/components", loader.DebugHandler(),
+//   ))
+//
+// A POST request with the form value refresh=<component> calls Refresh() for
+// the component. Such requests must carry an X-Requested-With header, which
+// browsers don't allow cross-origin pages to set without a CORS preflight, or
+// they are rejected with 403 Forbidden. This guards against other pages the
+// operator visits triggering refreshes, but the handler exposes internals and
+// must not be exposed publicly, only to operators.
+func (a *AcyclicLoader) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asJSON := r.URL.Query().Get("format") == "json" ||
+			strings.Contains(r.Header.Get("Accept"), "application/json")
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost:
+			if r.Header.Get("X-Requested-With") == "" {
+				http.Error(w, "missing X-Requested-With header", http.StatusForbidden)
+				return
+			}
+			name := r.FormValue("refresh")
+			err := a.Refresh(name)
+			if asJSON {
+				result := map[string]string{"refreshed": name}
+				if err != nil {
+					result["error"] = err.Error()
+				}
+				writeJSON(w, result)
+				return
+			}
+			if _, undefined := err.(*UndefinedComponentError); undefined {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+			return
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		status := a.status()
+		if asJSON {
+			writeJSON(w, status)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := debugTemplate.Execute(w, status); err != nil {
+			a.logger.Printf("warning: failed to render debug page: %s", err)
+		}
+	})
+}
+
+// status returns the status of all components, sorted by name.
+func (a *AcyclicLoader) status() []componentStatus {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	status := make([]componentStatus, 0, len(a.components))
+	for _, name := range sortedKeys(a.components) {
+		c := a.components[name]
+		s := componentStatus{
+			Name:         name,
+			Dependencies: sortedStrings(c.dependencies),
+			Tags:         c.tags,
+			Instances:    len(c.instances),
+		}
+		if c.result != nil {
+			s.Type = c.result.String()
+		}
+		switch {
+		case c.overwritten:
+			s.State = "overwritten"
+		case c.loaded && c.err != nil:
+			s.State = "failed"
+			s.Error = c.err.Error()
+		case c.loaded:
+			s.State = "loaded"
+		case c.loading:
+			s.State = "loading"
+		case c.inherited:
+			s.State = "inherited"
+		case len(c.instances) > 0:
+			s.State = "loaded"
+		default:
+			s.State = "unloaded"
+		}
+		if c.loaded && !c.overwritten {
+			s.LoadedAt = c.loadedAt
+			s.Duration = c.duration
+		}
+		status = append(status, s)
+	}
+	return status
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Components</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { padding: 4px 8px; border-bottom: 1px solid #ddd; text-align: left; vertical-align: top; }
+.failed { color: #b00; }
+pre { margin: 0; white-space: pre-wrap; }
+</style>
+<script>
+function refresh(name) {
+  fetch(location.pathname, {
+    method: "POST",
+    headers: {"X-Requested-With": "XMLHttpRequest"},
+    body: new URLSearchParams({refresh: name})
+  }).then(function() { location.reload(); });
+}
+</script>
+</head>
+<body>
+<h1>Components</h1>
+<table>
+<tr><th>Component</th><th>State</th><th>Dependencies</th><th>Loaded</th><th>Duration</th><th>Error</th><th></th></tr>
+{{range .}}<tr class="{{.State}}">
+<td>{{.Name}}</td>
+<td>{{.State}}{{if .Instances}} ({{.Instances}} instances){{end}}</td>
+<td>{{range $i, $dep := .Dependencies}}{{if $i}}, {{end}}{{$dep}}{{end}}</td>
+<td>{{if not .LoadedAt.IsZero}}{{.LoadedAt.Format "2006-01-02 15:04:05"}}{{end}}</td>
+<td>{{if .Duration}}{{.Duration}}{{end}}</td>
+<td><pre>{{.Error}}</pre></td>
+<td><button onclick="refresh({{.Name}})">Refresh</button></td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
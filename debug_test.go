@@ -0,0 +1,65 @@
+package acyclicloader
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	loads := 0
+	loader := Components{
+		"Port": func() int {
+			loads++
+			return 80
+		},
+		"Broken": func(options struct{ Port int }) (string, error) {
+			return "", errors.New("connection refused")
+		},
+		"Unused": func() string { return "unused" },
+	}.AsLoader()
+	loader.Load("Broken")
+	handler := loader.DebugHandler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/?format=json", nil))
+	var status []componentStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(status) != 3 || status[0].Name != "Broken" || status[0].State != "failed" ||
+		!strings.Contains(status[0].Error, "connection refused") ||
+		status[1].State != "loaded" || status[2].State != "unloaded" {
+		t.Error("unexpected status: ", status)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if body := w.Body.String(); !strings.Contains(body, "<td>Broken</td>") ||
+		!strings.Contains(body, "connection refused") {
+		t.Error("expected HTML with components and errors, got: ", body)
+	}
+
+	// Refreshes without X-Requested-With could come from any page in a browser
+	form := url.Values{"refresh": {"Port"}}
+	r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden || loads != 1 {
+		t.Error("expected refresh without X-Requested-With to be forbidden, got: ", w.Code, loads)
+	}
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusSeeOther || loads != 2 {
+		t.Error("expected 'Port' to be refreshed, got: ", w.Code, loads)
+	}
+}
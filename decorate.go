@@ -0,0 +1,286 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decorate returns an AcyclicLoader where the component given is wrapped by
+// decorator. This is useful for adding caching, logging or instrumentation
+// around a component without redefining it.
+//
+// The decorator must be a function that takes the original value, and
+// optionally a struct of other dependencies, and returns a value of the same
+// type, on the form:
+//   func (ComponentType) ComponentType
+//   func (ComponentType) (ComponentType, error)
+//   func (ComponentType, struct{Dependency DependencyType, ...}) ComponentType
+//   func (ComponentType, struct{Dependency DependencyType, ...}) (ComponentType, error)
+//
+// Dependencies of the decorator are merged with those of the component by the
+// name of the component depended on, and a ComponentDefinitionError is returned
+// if they disagree on type or tags. If the component is overwritten, the value
+// given to WithOverwrites() is decorated.
+//
+// Keyed components, components provided by Out fields and components inherited
+// from a parent loader cannot be decorated, and a ComponentDefinitionError is
+// returned for these.
+func (a *AcyclicLoader) Decorate(name string, decorator interface{}) (*AcyclicLoader, error) {
+	a.m.Lock()
+	if err := a.checkFrozen("Decorate"); err != nil {
+		a.m.Unlock()
+		return nil, err
+	}
+	c, ok := a.components[name]
+	if !ok {
+		defer a.m.Unlock()
+		return nil, a.checkOverwrites(map[string]interface{}{name: decorator})
+	}
+	definitionError := func(format string, args ...interface{}) error {
+		return &ComponentDefinitionError{
+			Component: name,
+			message:   fmt.Sprintf(format, args...),
+		}
+	}
+	if c.keyed {
+		a.m.Unlock()
+		return nil, definitionError("cannot decorate '%s' as it is a keyed component", name)
+	}
+	fn := a.definitions[name]
+	if c.overwritten && c.result != nil {
+		// Decorate the overwritten value, rather than the function it replaced
+		value := valueOf(c.result, c.value)
+		fnType := reflect.FuncOf(nil, []reflect.Type{c.result}, false)
+		fn = reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{value}
+		}).Interface()
+	}
+	if fn == nil {
+		defer a.m.Unlock()
+		if c.output != "" {
+			return nil, definitionError(
+				"cannot decorate '%s' as it is provided by an Out field of '%s', decorate '%s' instead",
+				name, c.output, c.output,
+			)
+		}
+		return nil, definitionError(
+			"cannot decorate '%s' as it is inherited from the parent loader, decorate it there instead", name,
+		)
+	}
+	a.m.Unlock()
+
+	if an, ok := fn.(*Annotated); ok {
+		fn = an.fn
+	}
+	if reflect.TypeOf(fn).Kind() != reflect.Func {
+		fn = valueFunc(fn)
+	}
+	fn = plainParamsFunc(fn)
+	decorated, err := decorate(name, c, fn, decorator)
+	if err != nil {
+		return nil, err
+	}
+	return a.WithOverwriteFuncs(map[string]interface{}{name: decorated})
+}
+
+// decorate returns a function that loads a component using fn and passes the
+// result to decorator, the returned function depends on the union of the
+// dependencies of fn and decorator.
+func decorate(name string, c *component, fn, decorator interface{}) (interface{}, error) {
+	definitionError := func(format string, args ...interface{}) error {
+		return &ComponentDefinitionError{
+			Component: name,
+			message:   fmt.Sprintf(format, args...),
+		}
+	}
+
+	d := reflect.ValueOf(decorator)
+	if d.Kind() != reflect.Func {
+		return nil, definitionError(
+			"expected decorator for '%s' to be a function, but found %T", name, decorator,
+		)
+	}
+	if c.result == nil {
+		return nil, definitionError("cannot decorate '%s' as it doesn't have a result", name)
+	}
+	t := d.Type()
+	if t.NumIn() < 1 || t.NumIn() > 2 || t.In(0) != c.result {
+		return nil, definitionError(
+			"expected decorator for '%s' to take %s and optionally a struct, but found %s",
+			name, c.result.String(), t.String(),
+		)
+	}
+	if t.NumOut() < 1 || t.NumOut() > 2 || t.Out(0) != c.result ||
+		(t.NumOut() == 2 && t.Out(1) != typeOfError) {
+		return nil, definitionError(
+			"expected decorator for '%s' to return %s and optionally an error, but found %s",
+			name, c.result.String(), t.String(),
+		)
+	}
+
+	// Create an options struct with fields from both fn and decorator
+	var fnOptions, decoratorOptions reflect.Type
+	if f := reflect.TypeOf(fn); f.NumIn() == 1 {
+		fnOptions = f.In(0)
+	}
+	if t.NumIn() == 2 {
+		decoratorOptions = t.In(1)
+		if optionsStruct(decoratorOptions) == nil {
+			return nil, definitionError(
+				"expected 2nd input parameter for decorator of '%s' to be a struct or pointer to a struct, but found %s",
+				name, decoratorOptions.String(),
+			)
+		}
+	}
+	// Fields are merged by the name of the component they resolve to, fields of
+	// fn keep their names, as annotations such as RenameDependency() refer to
+	// them, while other fields are renamed if their name is taken.
+	type mergedField struct {
+		key     string
+		byType  bool // Info, context and fields wired by type are merged by type
+		flags   []string
+		def     string
+		hasDef  bool
+		field   reflect.StructField
+		sources []string // descriptions of fields merged, for errors
+	}
+	var merged []*mergedField
+	taken := map[string]bool{}
+	var mappings [2][][2][]int // index in options of fn and decorator to index in merged
+	var err error
+	for i, options := range []reflect.Type{fnOptions, decoratorOptions} {
+		if options == nil {
+			continue
+		}
+		owner := "'" + name + "'"
+		if i == 1 {
+			owner = "decorator for '" + name + "'"
+		}
+		walkDependencyFields(optionsStruct(options), nil, func(field reflect.StructField, index []int) bool {
+			if field.PkgPath != "" {
+				err = definitionError(
+					"cannot decorate '%s' as dependency '%s' is an unexported field",
+					name, field.Name,
+				)
+				return false
+			}
+			key, flags := parseComponentTag(field)
+			if renamed, ok := c.renamed[field.Name]; ok && i == 0 {
+				key = renamed
+			}
+			special := field.Type == typeOfInfo || field.Type == typeOfContext
+			if special {
+				flags = nil
+			}
+			byType := special || stringContains(flags, "bytype")
+			if byType {
+				key = field.Type.String()
+			}
+			def, hasDef := field.Tag.Lookup("default")
+			var m *mergedField
+			for j, f := range merged {
+				if f.key == key && f.byType == byType {
+					m = f
+					mappings[i] = append(mappings[i], [2][]int{index, {j}})
+					break
+				}
+			}
+			source := fmt.Sprintf("%s expects '%s'", owner, field.Name)
+			if m != nil {
+				if m.field.Type != field.Type {
+					err = definitionError(
+						"%s to have type %s, but %s to have type %s, both depend on '%s'",
+						source, field.Type.String(), m.sources[0], m.field.Type.String(), key,
+					)
+					return false
+				}
+				if strings.Join(m.flags, ",") != strings.Join(flags, ",") || m.def != def || m.hasDef != hasDef {
+					err = definitionError(
+						"%s to have tag `%s`, but %s to have tag `%s`, both depend on '%s'",
+						source, field.Tag, m.sources[0], m.field.Tag, key,
+					)
+					return false
+				}
+				m.sources = append(m.sources, source)
+				return true
+			}
+
+			// Embedded structs are flattened, so we only copy name, type and tag
+			f := reflect.StructField{Name: field.Name, Type: field.Type, Tag: field.Tag}
+			for n := 2; taken[f.Name]; n++ {
+				f.Name = fmt.Sprintf("%s%d", field.Name, n)
+			}
+			if f.Name != field.Name && !special {
+				// Renamed fields must still depend on the same component
+				tag := fmt.Sprintf("component:%s", strconv.Quote(strings.Join(append([]string{key}, flags...), ",")))
+				if hasDef {
+					tag += fmt.Sprintf(" default:%s", strconv.Quote(def))
+				}
+				f.Tag = reflect.StructTag(tag)
+			}
+			taken[f.Name] = true
+			mappings[i] = append(mappings[i], [2][]int{index, {len(merged)}})
+			merged = append(merged, &mergedField{
+				key:     key,
+				byType:  byType,
+				flags:   flags,
+				def:     def,
+				hasDef:  hasDef,
+				field:   f,
+				sources: []string{source},
+			})
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	fields := make([]reflect.StructField, len(merged))
+	for i, m := range merged {
+		fields[i] = m.field
+	}
+
+	// copyFields creates an argument of type target with fields copied from options
+	copyFields := func(options reflect.Value, target reflect.Type, mapping [][2][]int) reflect.Value {
+		arg, v := newOptions(target)
+		for _, m := range mapping {
+			v.FieldByIndex(m[0]).Set(options.FieldByIndex(m[1]))
+		}
+		return arg
+	}
+
+	var in []reflect.Type
+	if len(fields) > 0 {
+		in = []reflect.Type{reflect.StructOf(fields)}
+	}
+	out := []reflect.Type{c.result, typeOfError}
+	return reflect.MakeFunc(reflect.FuncOf(in, out, false), func(args []reflect.Value) []reflect.Value {
+		var options reflect.Value
+		if len(args) > 0 {
+			options = args[0]
+		}
+
+		// Load the original value
+		var fnArgs []reflect.Value
+		if fnOptions != nil {
+			fnArgs = []reflect.Value{copyFields(options, fnOptions, mappings[0])}
+		}
+		ret := reflect.ValueOf(fn).Call(fnArgs)
+		if len(ret) == 2 && !ret[1].IsNil() {
+			return ret
+		}
+
+		// Decorate the original value
+		decoratorArgs := []reflect.Value{ret[0]}
+		if decoratorOptions != nil {
+			decoratorArgs = append(decoratorArgs, copyFields(options, decoratorOptions, mappings[1]))
+		}
+		ret = d.Call(decoratorArgs)
+		if len(ret) == 1 {
+			ret = append(ret, reflect.Zero(typeOfError))
+		}
+		return ret
+	}).Interface(), nil
+}
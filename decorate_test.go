@@ -0,0 +1,143 @@
+package acyclicloader
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecorate(t *testing.T) {
+	loader, _ := New(Components{
+		"Prefix": func() string { return "> " },
+		"Suffix": func() string { return "!" },
+		"Message": func(options struct{ Prefix string }) string {
+			return options.Prefix + "hello"
+		},
+		"Output": func(options struct{ Message string }) string {
+			return options.Message + "\n"
+		},
+	})
+
+	decorated, err := loader.Decorate("Message", func(message string, options struct {
+		Prefix string
+		Suffix string
+	}) string {
+		return message + options.Suffix
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if decorated.MustLoad("Output").(string) != "> hello!\n" {
+		t.Error("expected '> hello!\\n'")
+	}
+	if loader.MustLoad("Output").(string) != "> hello\n" {
+		t.Error("expected '> hello\\n'")
+	}
+
+	// Decorators can fail
+	decorated, _ = loader.Decorate("Message", func(string) (string, error) {
+		return "", errors.New("decorator failed")
+	})
+	_, err = decorated.Load("Output")
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	// Decorators must return the same type
+	_, err = loader.Decorate("Message", func(string) int { return 5 })
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestDecorateMergesByComponent(t *testing.T) {
+	loader, _ := New(Components{
+		"Prefix": func() string { return "> " },
+		"Suffix": func() string { return "!" },
+		"Message": func(options struct {
+			Value string `component:"Prefix"`
+		}) string {
+			return options.Value + "hello"
+		},
+	})
+
+	// Fields with the same name depending on different components are kept apart
+	decorated, err := loader.Decorate("Message", func(message string, options struct {
+		Value  string `component:"Suffix"`
+		Prefix string
+	}) string {
+		return message + options.Value + options.Prefix
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if v := decorated.MustLoad("Message").(string); v != "> hello!> " {
+		t.Errorf("expected '> hello!> ', got '%s'", v)
+	}
+
+	// Fields depending on the same component must agree on tags
+	_, err = loader.Decorate("Message", func(message string, options struct {
+		Prefix string `component:",optional"`
+	}) string {
+		return message
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*ComponentDefinitionError); !ok {
+		t.Error("expected a ComponentDefinitionError for conflicting tags")
+	}
+}
+
+func TestDecorateOverwritten(t *testing.T) {
+	loader, _ := New(Components{
+		"Message": func() string { return "hello" },
+		"Output": func(options struct{ Message string }) string {
+			return options.Message + "\n"
+		},
+	})
+	overwritten, err := loader.WithOverwrites(map[string]interface{}{"Message": "hi"})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	decorated, err := overwritten.Decorate("Message", func(message string) string {
+		return message + "!"
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if v := decorated.MustLoad("Output").(string); v != "hi!\n" {
+		t.Errorf("expected the overwritten value to be decorated, got '%s'", v)
+	}
+}
+
+func TestDecorateUndecoratable(t *testing.T) {
+	parent := Components{
+		"Region": "eu-west-1",
+		"Clients": func(options struct{ Region string }) *testClients {
+			return &testClients{S3: "s3." + options.Region}
+		},
+		"TenantDB": Annotate(func(tenant string) string { return tenant }, Keyed()),
+	}.AsLoader()
+	child, err := parent.Child(Components{
+		"Uploader": func(options struct{ Region string }) string { return options.Region },
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+
+	cases := []struct {
+		loader    *AcyclicLoader
+		component string
+	}{
+		{parent, "S3"},       // provided by an Out field of 'Clients'
+		{parent, "TenantDB"}, // keyed
+		{child, "Region"},    // inherited from the parent loader
+	}
+	for _, c := range cases {
+		_, err := c.loader.Decorate(c.component, func(value string) string { return value })
+		t.Logf("got error as expected: '%s'", err)
+		if _, ok := err.(*ComponentDefinitionError); !ok {
+			t.Errorf("expected a ComponentDefinitionError for '%s'", c.component)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// EnvString returns a function loading a component from the environment
+// variable name, or def if the variable isn't set.
+//
+//   "Host": acyclicloader.EnvString("HOST", "localhost"),
+func EnvString(name, def string) func() string {
+	return func() string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return def
+	}
+}
+
+// EnvInt returns a function loading an int component from the environment
+// variable name, or def if the variable isn't set. Loading the component fails,
+// if the variable isn't a valid integer.
+func EnvInt(name string, def int) func() (int, error) {
+	return func() (int, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return def, nil
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer in environment variable %s: %w", name, err)
+		}
+		return i, nil
+	}
+}
+
+// EnvBool returns a function loading a bool component from the environment
+// variable name, or def if the variable isn't set. This accepts the values
+// supported by strconv.ParseBool(), and is useful for feature flags, see
+// EnabledBy().
+func EnvBool(name string, def bool) func() (bool, error) {
+	return func() (bool, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return def, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid boolean in environment variable %s: %w", name, err)
+		}
+		return b, nil
+	}
+}
+
+// EnvDuration returns a function loading a time.Duration component from the
+// environment variable name, or def if the variable isn't set. This accepts
+// the values supported by time.ParseDuration(), such as "1m30s".
+func EnvDuration(name string, def time.Duration) func() (time.Duration, error) {
+	return func() (time.Duration, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return def, nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration in environment variable %s: %w", name, err)
+		}
+		return d, nil
+	}
+}
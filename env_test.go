@@ -0,0 +1,44 @@
+package acyclicloader
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEnv(t *testing.T) {
+	os.Setenv("ACYCLICLOADER_TEST_HOST", "example.com")
+	os.Setenv("ACYCLICLOADER_TEST_PORT", "443")
+	os.Setenv("ACYCLICLOADER_TEST_DEBUG", "true")
+	os.Setenv("ACYCLICLOADER_TEST_INVALID", "not-a-number")
+	defer os.Unsetenv("ACYCLICLOADER_TEST_HOST")
+	defer os.Unsetenv("ACYCLICLOADER_TEST_PORT")
+	defer os.Unsetenv("ACYCLICLOADER_TEST_DEBUG")
+	defer os.Unsetenv("ACYCLICLOADER_TEST_INVALID")
+
+	loader := Components{
+		"Host":    EnvString("ACYCLICLOADER_TEST_HOST", "localhost"),
+		"Port":    EnvInt("ACYCLICLOADER_TEST_PORT", 80),
+		"Debug":   EnvBool("ACYCLICLOADER_TEST_DEBUG", false),
+		"Timeout": EnvDuration("ACYCLICLOADER_TEST_TIMEOUT", time.Minute),
+		"Invalid": EnvInt("ACYCLICLOADER_TEST_INVALID", 0),
+	}.AsLoader()
+
+	if loader.MustLoad("Host").(string) != "example.com" {
+		t.Error("expected 'example.com'")
+	}
+	if loader.MustLoad("Port").(int) != 443 {
+		t.Error("expected 443")
+	}
+	if !loader.MustLoad("Debug").(bool) {
+		t.Error("expected true")
+	}
+	if loader.MustLoad("Timeout").(time.Duration) != time.Minute {
+		t.Error("expected default value of 1m")
+	}
+	_, err := loader.Load("Invalid")
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
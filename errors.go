@@ -1,35 +1,381 @@
 package acyclicloader
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
 )
 
 // A DependencyLoadError indicates that a dependency of a component failed to load.
 type DependencyLoadError struct {
-	trace []string
-	err   error
+	trace     []string
+	durations []time.Duration
+	err       error
 }
 
-func (e *DependencyLoadError) extend(component string) error {
+func (e *DependencyLoadError) extend(component string, elapsed time.Duration) *DependencyLoadError {
 	return &DependencyLoadError{
-		trace: append([]string{component}, e.trace...),
-		err:   e.err,
+		trace:     append([]string{component}, e.trace...),
+		durations: append([]time.Duration{elapsed}, e.durations...),
+		err:       e.err,
 	}
 }
 
+// Error returns a message with the trace, annotated with the duration of each
+// hop and whether the last dependency failed with a timeout, panic or error:
+//   failed to load dependency Server (2s) -> Database (2s, timeout): ...
 func (e *DependencyLoadError) Error() string {
-	return fmt.Sprintf("failed to load dependency %s: %s", strings.Join(e.trace, " -> "), e.err)
+	hops := make([]string, len(e.trace))
+	for i, name := range e.trace {
+		hops[i] = fmt.Sprintf("%s (%s)", name, e.durations[i])
+	}
+	last := len(hops) - 1
+	hops[last] = fmt.Sprintf("%s (%s, %s)", e.trace[last], e.durations[last], describeFailure(e.err))
+	return fmt.Sprintf("failed to load dependency %s: %s", strings.Join(hops, " -> "), e.err)
+}
+
+// Unwrap returns the error from the dependency that failed to load, this is
+// the root cause of the error and not another DependencyLoadError.
+func (e *DependencyLoadError) Unwrap() error {
+	return e.err
+}
+
+// Trace returns the chain of components from the component being loaded to the
+// dependency that failed to load.
+func (e *DependencyLoadError) Trace() []string {
+	return append([]string(nil), e.trace...)
+}
+
+// Durations returns the time spent loading each component in Trace() before
+// it failed. For the last dependency this is the time spent in the function
+// loading it, for the others it includes waiting for dependencies.
+func (e *DependencyLoadError) Durations() []time.Duration {
+	return append([]time.Duration(nil), e.durations...)
+}
+
+// describeFailure returns "panic", "timeout" or "error" describing err.
+func describeFailure(err error) string {
+	var panicErr *PanicError
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.As(err, &panicErr):
+		return "panic"
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &timeout) && timeout.Timeout():
+		return "timeout"
+	}
+	return "error"
+}
+
+// A PanicError indicates that the function loading a component panicked, the
+// panic is recovered such that dependents fail with a DependencyLoadError.
+type PanicError struct {
+	Value interface{} // value passed to panic()
+	Stack []byte      // stack trace of the goroutine that panicked
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic while loading component: %v", e.Value)
+}
+
+// A MultiDependencyLoadError indicates that several dependencies of a
+// component failed to load, listing the failure of each in field order.
+type MultiDependencyLoadError struct {
+	Errors []*DependencyLoadError
+}
+
+func (e *MultiDependencyLoadError) extend(component string, elapsed time.Duration) error {
+	errs := make([]*DependencyLoadError, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err.extend(component, elapsed)
+	}
+	return &MultiDependencyLoadError{Errors: errs}
+}
+
+func (e *MultiDependencyLoadError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf(
+		"failed to load %d dependencies:\n  %s", len(e.Errors), strings.Join(messages, "\n  "),
+	)
+}
+
+// Unwrap returns the errors from each dependency that failed to load, for use
+// with errors.Is() and errors.As() from Go 1.20, see also Is() and As().
+func (e *MultiDependencyLoadError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Is returns true, if the error from any dependency that failed to load is
+// target, such that errors.Is() finds it before Go 1.20 too.
+func (e *MultiDependencyLoadError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error from a dependency that failed to load matching
+// target, such that errors.As() finds it before Go 1.20 too.
+func (e *MultiDependencyLoadError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// A ReentrantLoadError indicates that a function loading a component called
+// Load() for a component depending on it, which would deadlock.
+type ReentrantLoadError struct {
+	// Chain of components loaded by the goroutine, starting with the component
+	// depended upon, followed by the component passed to Load() and its
+	// dependencies leading back to the first component.
+	Chain []string
+}
+
+func (e *ReentrantLoadError) Error() string {
+	return fmt.Sprintf(
+		"re-entrant load would deadlock: '%s'", strings.Join(e.Chain, "' -> '"),
+	)
+}
+
+// A StartupBudgetError indicates that LoadAll() didn't load all components
+// within the duration given to StartupBudget().
+type StartupBudgetError struct {
+	Budget time.Duration
+	// Sorted list of components loaded, or failed, within the budget
+	Finished []string
+	// Sorted list of components whose function was still running
+	InFlight []string
+	// Sorted list of components whose function wasn't called, as they were
+	// waiting for dependencies
+	NotStarted []string
+}
+
+func (e *StartupBudgetError) Error() string {
+	return fmt.Sprintf(
+		"startup exceeded budget of %s with %d components finished, in flight: '%s', not started: '%s'",
+		e.Budget, len(e.Finished), strings.Join(e.InFlight, "', '"), strings.Join(e.NotStarted, "', '"),
+	)
+}
+
+// Unwrap returns context.DeadlineExceeded, such that errors.Is() can detect
+// that startup timed out.
+func (e *StartupBudgetError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// A NilResultError indicates that the function loading a component returned
+// nil without an error, see WithNilResultsAsErrors().
+type NilResultError struct {
+	Component string
+	Type      reflect.Type
+}
+
+func (e *NilResultError) Error() string {
+	return fmt.Sprintf(
+		"component '%s' loaded a nil %v without an error, use AllowNil() if nil is valid",
+		e.Component, e.Type,
+	)
+}
+
+// A MiddlewareResultError indicates that a Middleware returned a value, which
+// doesn't have the type of the component being loaded.
+type MiddlewareResultError struct {
+	Component string
+	Type      reflect.Type // type of the value returned by the middleware
+	Expected  reflect.Type // type of the component
+}
+
+func (e *MiddlewareResultError) Error() string {
+	return fmt.Sprintf(
+		"middleware returned a value of type %v for component '%s', which has type %v",
+		e.Type, e.Component, e.Expected,
+	)
 }
 
 // An UndefinedComponentError indicates that Load() was given a component which
 // wasn't defined.
 type UndefinedComponentError struct {
 	Component string
+	// Defined components with names similar to Component, nearest first
+	Suggestions []string
+	// Sorted list of all defined components
+	Available []string
 }
 
 func (e *UndefinedComponentError) Error() string {
-	return fmt.Sprintf("cannot load undefined component '%s'", e.Component)
+	return fmt.Sprintf(
+		"cannot load undefined component '%s'%s", e.Component,
+		describeAlternatives(e.Suggestions, e.Available),
+	)
+}
+
+// A DisabledComponentError indicates that a component wasn't loaded because it
+// is disabled by a feature flag, see EnabledBy().
+type DisabledComponentError struct {
+	Component string
+	Flag      string
+}
+
+func (e *DisabledComponentError) Error() string {
+	return fmt.Sprintf("component '%s' is disabled by '%s'", e.Component, e.Flag)
+}
+
+// A ForbiddenComponentError indicates that a component was loaded, though it
+// was forbidden by Strict() and hasn't been overwritten.
+type ForbiddenComponentError struct {
+	Component string
+}
+
+func (e *ForbiddenComponentError) Error() string {
+	return fmt.Sprintf(
+		"cannot load component '%s' in strict mode, it must be overwritten", e.Component,
+	)
+}
+
+// A FrozenError indicates that an AcyclicLoader couldn't be modified, because
+// it has been frozen, see Freeze().
+type FrozenError struct {
+	Operation string // name of the method called, such as "WithOverwrites"
+}
+
+func (e *FrozenError) Error() string {
+	return fmt.Sprintf("cannot call %s() on a frozen loader", e.Operation)
+}
+
+// A KeyedComponentError indicates that Load() was given a keyed component, or
+// that LoadKeyed() was given a component that isn't keyed, see Keyed().
+type KeyedComponentError struct {
+	Component string
+	Keyed     bool // true, if Component is keyed
+}
+
+func (e *KeyedComponentError) Error() string {
+	if e.Keyed {
+		return fmt.Sprintf("cannot load keyed component '%s' without a key, use LoadKeyed()", e.Component)
+	}
+	return fmt.Sprintf("cannot load component '%s' with a key, as it isn't keyed", e.Component)
+}
+
+// An UndefinedOverwriteError indicates that WithOverwrites() was given values
+// for components which aren't defined.
+type UndefinedOverwriteError struct {
+	// Sorted list of undefined components given to WithOverwrites()
+	Components []string
+	// Defined components with names similar to each undefined component
+	Suggestions map[string][]string
+	// Sorted list of all defined components
+	Available []string
+}
+
+func (e *UndefinedOverwriteError) Error() string {
+	descriptions := make([]string, len(e.Components))
+	for i, name := range e.Components {
+		descriptions[i] = fmt.Sprintf("'%s'", name)
+		if suggestions := e.Suggestions[name]; len(suggestions) > 0 {
+			descriptions[i] += fmt.Sprintf(
+				" (did you mean '%s'?)", strings.Join(suggestions, "' or '"),
+			)
+		}
+	}
+	return fmt.Sprintf(
+		"cannot overwrite undefined components: %s%s",
+		strings.Join(descriptions, ", "), describeAlternatives(nil, e.Available),
+	)
+}
+
+// A MergeConflictError indicates that Components.Merge() was given sets of
+// components that both define the same components.
+type MergeConflictError struct {
+	// Sorted list of components defined in both sets
+	Components []string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf(
+		"cannot merge components, conflicting definitions of '%s'",
+		strings.Join(e.Components, "', '"),
+	)
+}
+
+// A TypeResolutionError indicates that a component was requested by type, but
+// there isn't exactly one component with said type.
+type TypeResolutionError struct {
+	Type reflect.Type
+	// Sorted list of components with the requested type
+	Components []string
+}
+
+func (e *TypeResolutionError) Error() string {
+	if len(e.Components) == 0 {
+		return fmt.Sprintf("no component has type %v", e.Type)
+	}
+	return fmt.Sprintf(
+		"ambiguous type %v, provided by components '%s'",
+		e.Type, strings.Join(e.Components, "', '"),
+	)
+}
+
+// A ShutdownError is returned by Shutdown() if one or more components failed
+// to close.
+type ShutdownError struct {
+	// Errors returned by Close(), indexed by component name
+	Errors map[string]error
+}
+
+func (e *ShutdownError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = fmt.Sprintf("'%s': %s", name, e.Errors[name])
+	}
+	return fmt.Sprintf("failed to shutdown components:\n  %s", strings.Join(messages, "\n  "))
+}
+
+// A BuildError is returned by Builder.Build() listing all errors encountered
+// while adding components.
+type BuildError struct {
+	Errors []error
+}
+
+func (e *BuildError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("failed to build loader:\n  %s", strings.Join(messages, "\n  "))
+}
+
+// describeAlternatives returns a string suggesting alternatives to an
+// undefined component, suitable for appending to an error message.
+func describeAlternatives(suggestions, available []string) string {
+	var s string
+	if len(suggestions) > 0 {
+		s += fmt.Sprintf(", did you mean '%s'?", strings.Join(suggestions, "' or '"))
+	}
+	if len(available) > 0 {
+		s += fmt.Sprintf(" (available components: '%s')", strings.Join(available, "', '"))
+	}
+	return s
 }
 
 // A ComponentDefinitionError is returned if the definition of components
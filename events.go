@@ -0,0 +1,122 @@
+package acyclicloader
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// An EventKind identifies what happened to a component, see Events().
+type EventKind int
+
+const (
+	// LoadQueued is emitted when a component needs to be loaded, before its
+	// dependencies are loaded.
+	LoadQueued EventKind = iota
+	// LoadStarted is emitted when the function loading a component is called,
+	// after its dependencies have been loaded.
+	LoadStarted
+	// LoadFinished is emitted when a component has been loaded.
+	LoadFinished
+	// LoadFailed is emitted when a component failed to load, Err holds the error.
+	LoadFailed
+	// Refreshed is emitted when Refresh() has loaded a component again.
+	Refreshed
+	// ShutdownStarted is emitted when Shutdown() is called, Component is empty.
+	ShutdownStarted
+	// LoadSlow is emitted when the function loading a component is slow, see
+	// WithSlowThreshold(), Duration holds the time spent so far.
+	LoadSlow
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case LoadQueued:
+		return "LoadQueued"
+	case LoadStarted:
+		return "LoadStarted"
+	case LoadFinished:
+		return "LoadFinished"
+	case LoadFailed:
+		return "LoadFailed"
+	case Refreshed:
+		return "Refreshed"
+	case ShutdownStarted:
+		return "ShutdownStarted"
+	case LoadSlow:
+		return "LoadSlow"
+	}
+	return "EventKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// An Event describes something that happened to a component, see Events().
+type Event struct {
+	Kind      EventKind
+	Component string
+	Time      time.Time
+	// Duration of the function loading the component, for LoadFinished,
+	// LoadFailed and LoadSlow events
+	Duration time.Duration
+	Err      error // error for LoadFailed and Refreshed events, if any
+}
+
+// eventBuffer is the number of events buffered for each channel from Events().
+const eventBuffer = 256
+
+// Events returns a channel receiving events from a from now on, until ctx is
+// done, at which point the channel is closed. This is useful for dashboards and
+// progress bars during startup.
+//
+//   events := loader.Events(ctx)
+//   go func() {
+//       for e := range events {
+//           log.Printf("%s %s", e.Kind, e.Component)
+//       }
+//   }()
+//
+// Events are sent without blocking, if the receiver falls more than 256 events
+// behind, further events are dropped until it catches up. Channels are not
+// inherited by clones of a. Cached components don't emit events, as they are
+// not loaded again.
+func (a *AcyclicLoader) Events(ctx context.Context) <-chan Event {
+	ch := make(chan Event, eventBuffer)
+	a.m.Lock()
+	a.events = append(a.events[:len(a.events):len(a.events)], ch)
+	a.m.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		a.m.Lock()
+		defer a.m.Unlock()
+		events := make([]chan Event, 0, len(a.events))
+		for _, other := range a.events {
+			if other != ch {
+				events = append(events, other)
+			}
+		}
+		a.events = events
+		close(ch)
+	}()
+	return ch
+}
+
+// emit sends an event to channels from Events(), this must be called with a.m
+// locked.
+func (a *AcyclicLoader) emit(kind EventKind, component string, duration time.Duration, err error) {
+	if len(a.events) == 0 {
+		return
+	}
+	e := Event{
+		Kind:      kind,
+		Component: component,
+		Time:      time.Now(),
+		Duration:  duration,
+		Err:       err,
+	}
+	for _, ch := range a.events {
+		select {
+		case ch <- e:
+		default: // drop events rather than block loading
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package acyclicloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	failure := errors.New("connection refused")
+	loader := Components{
+		"Config":   func() string { return "config" },
+		"Database": func(options struct{ Config string }) (int, error) { return 0, failure },
+		"Server":   func(options struct{ Config string }) string { return "server" },
+	}.AsLoader()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := loader.Events(ctx)
+
+	loader.MustLoad("Server")
+	loader.Load("Database")
+	loader.Refresh("Config")
+	loader.Shutdown()
+
+	var got []string
+	for len(events) > 0 {
+		e := <-events
+		if e.Time.IsZero() {
+			t.Error("expected events to have a timestamp")
+		}
+		if e.Kind == LoadFailed && !errors.Is(e.Err, failure) {
+			t.Error("expected LoadFailed to have the error, got: ", e.Err)
+		}
+		got = append(got, e.Kind.String()+" "+e.Component)
+	}
+	expected := []string{
+		"LoadQueued Server",
+		"LoadQueued Config",
+		"LoadStarted Config",
+		"LoadFinished Config",
+		"LoadStarted Server",
+		"LoadFinished Server",
+		"LoadQueued Database",
+		"LoadStarted Database",
+		"LoadFailed Database",
+	}
+	if len(got) < len(expected) {
+		t.Fatal("expected more events, got: ", got)
+	}
+	for i, e := range expected {
+		if got[i] != e {
+			t.Errorf("expected event %d to be %q, got: %q", i, e, got[i])
+		}
+	}
+	if last := got[len(got)-1]; last != "ShutdownStarted " {
+		t.Error("expected last event to be ShutdownStarted, got: ", last)
+	}
+	if !stringContains(got, "Refreshed Config") || !stringContains(got, "Refreshed Server") {
+		t.Error("expected Refreshed events, got: ", got)
+	}
+}
+
+func TestEventsCancel(t *testing.T) {
+	loader := Components{
+		"Config": func() string { return "config" },
+	}.AsLoader()
+	ctx, cancel := context.WithCancel(context.Background())
+	events := loader.Events(ctx)
+	loader.MustLoad("Config")
+	cancel()
+
+	// The channel is closed once ctx is done, so ranging over it finishes
+	n := 0
+	for range events {
+		n++
+	}
+	if n != 3 {
+		t.Error("expected the events from loading 'Config', got: ", n)
+	}
+	if len(loader.events) != 0 {
+		t.Error("expected the channel to be unsubscribed")
+	}
+}
@@ -77,10 +77,14 @@ func testWithOverwrites(t *testing.T) {
 
 	// With overwrites we inject values for Port and Database, so that these
 	// aren't loaded. This is a great way to inject mock objects.
-	server := components.WithOverwrites(map[string]interface{}{
+	loader, err := components.WithOverwrites(map[string]interface{}{
 		"Port":     60000, // This port is better when testing
 		"Database": db,    // Use a different database in our tests
-	}).MustLoad("Server").(*http.Server)
+	})
+	if err != nil {
+		t.Fatal(err) // Overwriting an undefined component is an error
+	}
+	server := loader.MustLoad("Server").(*http.Server)
 
 	go server.ListenAndServe()
 	defer server.Close()
@@ -0,0 +1,204 @@
+// Package federation provides an experimental layer for linking loaders
+// across process boundaries.
+//
+// A process can serve components from its loader over a small RPC protocol,
+// and another process can declare components as remote, in which case they
+// are resolved by calling the serving process. Remote components must have
+// types that can be serialized with encoding/gob.
+//
+//   // In the process providing "Config"
+//   l, _ := net.Listen("tcp", ":7000")
+//   go federation.NewServer(loader, "Config").Serve(l)
+//
+//   // In the process consuming "Config"
+//   client, _ := federation.Dial("tcp", "localhost:7000")
+//   var loader = acyclicloader.Components{
+//       "Config": federation.Remote(client, "Config", &Config{}),
+//       ...
+//   }.AsLoader()
+package federation
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"net"
+	"net/rpc"
+	"reflect"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+const serviceName = "AcyclicLoader"
+
+var typeOfError = reflect.TypeOf((*error)(nil)).Elem()
+
+// A Server serves components from an AcyclicLoader to remote processes.
+type Server struct {
+	rpc *rpc.Server
+}
+
+type service struct {
+	loader   *acyclicloader.AcyclicLoader
+	exported []string
+}
+
+// NewServer returns a Server exposing the given components from loader, if
+// no components are given all components are exposed.
+func NewServer(loader *acyclicloader.AcyclicLoader, components ...string) *Server {
+	s := rpc.NewServer()
+	err := s.RegisterName(serviceName, &service{
+		loader:   loader,
+		exported: components,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to register rpc service: %s", err))
+	}
+	return &Server{rpc: s}
+}
+
+// Serve accepts connections on listener and serves requests for components,
+// this blocks until listener.Accept() fails.
+func (s *Server) Serve(listener net.Listener) {
+	s.rpc.Accept(listener)
+}
+
+// ServeConn serves requests for components on a single connection, this blocks
+// until the client hangs up.
+func (s *Server) ServeConn(conn net.Conn) {
+	s.rpc.ServeConn(conn)
+}
+
+// Load is the RPC method loading a component and returning its value encoded
+// with encoding/gob.
+func (s *service) Load(component string, reply *[]byte) error {
+	if len(s.exported) > 0 && !contains(s.exported, component) {
+		return fmt.Errorf("component '%s' is not exported", component)
+	}
+	value, err := s.loader.Load(component)
+	if err != nil {
+		return err
+	}
+	data, err := encode(value)
+	if err != nil {
+		return fmt.Errorf("failed to serialize component '%s': %s", component, err)
+	}
+	*reply = data
+	return nil
+}
+
+// encode value with encoding/gob, net/rpc doesn't recover panics in methods
+// so nil values and panics from gob are returned as errors.
+func encode(value interface{}) (data []byte, err error) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("cannot encode nil value")
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, fmt.Errorf("cannot encode nil pointer of type %s", v.Type())
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).EncodeValue(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// A Client connects to a remote Server.
+type Client struct {
+	rpc     *rpc.Client
+	address string
+}
+
+// Dial connects to a Server at the given network address.
+func Dial(network, address string) (*Client, error) {
+	c, err := rpc.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{rpc: c, address: address}, nil
+}
+
+// NewClient returns a Client communicating with a Server over conn.
+func NewClient(conn net.Conn) *Client {
+	return &Client{
+		rpc:     rpc.NewClient(conn),
+		address: conn.RemoteAddr().String(),
+	}
+}
+
+// Close the connection to the remote Server.
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// Load a component from the remote Server into the value pointed to by target.
+func (c *Client) Load(component string, target interface{}) error {
+	var data []byte
+	if err := c.rpc.Call(serviceName+".Load", component, &data); err != nil {
+		return &RemoteError{
+			Component: component,
+			Address:   c.address,
+			message:   err.Error(),
+		}
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(target); err != nil {
+		return &RemoteError{
+			Component: component,
+			Address:   c.address,
+			message:   fmt.Sprintf("failed to deserialize: %s", err),
+		}
+	}
+	return nil
+}
+
+// Remote returns a function loading component from the remote Server, suitable
+// for use as definition in acyclicloader.Components. The type of the component
+// is the type of prototype, for example:
+//   "Port": federation.Remote(client, "Port", 0),
+//   "Config": federation.Remote(client, "Config", &Config{}),
+func Remote(c *Client, component string, prototype interface{}) interface{} {
+	t := reflect.TypeOf(prototype)
+	if t == nil {
+		panic("federation.Remote() requires a non-nil prototype")
+	}
+	fnType := reflect.FuncOf(nil, []reflect.Type{t, typeOfError}, false)
+	return reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+		target := reflect.New(t)
+		err := c.Load(component, target.Interface())
+		errValue := reflect.Zero(typeOfError)
+		if err != nil {
+			errValue = reflect.ValueOf(&err).Elem()
+		}
+		return []reflect.Value{target.Elem(), errValue}
+	}).Interface()
+}
+
+// A RemoteError indicates that a component couldn't be loaded from a remote
+// Server.
+type RemoteError struct {
+	Component string
+	Address   string
+	message   string
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf(
+		"failed to load remote component '%s' from %s: %s",
+		e.Component, e.Address, e.message,
+	)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package federation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+type config struct {
+	Name string
+	Port int
+}
+
+func TestRemoteComponents(t *testing.T) {
+	remote := acyclicloader.Components{
+		"Config": func() *config {
+			return &config{Name: "remote", Port: 8080}
+		},
+		"Secret": func() string { return "hidden" },
+		"Broken": func() (int, error) {
+			return 0, errors.New("broken component")
+		},
+	}.AsLoader()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go NewServer(remote, "Config", "Broken").Serve(l)
+
+	client, err := Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	loader := acyclicloader.Components{
+		"Config": Remote(client, "Config", &config{}),
+		"Secret": Remote(client, "Secret", ""),
+		"Broken": Remote(client, "Broken", 0),
+		"Address": func(options struct{ Config *config }) string {
+			return fmt.Sprintf("%s:%d", options.Config.Name, options.Config.Port)
+		},
+	}.AsLoader()
+
+	if loader.MustLoad("Address").(string) != "remote:8080" {
+		t.Error("expected 'remote:8080'")
+	}
+
+	_, err = loader.Load("Secret")
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "not exported") {
+		t.Error("expected an error as 'Secret' isn't exported")
+	}
+
+	_, err = loader.Load("Broken")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*RemoteError); !ok {
+		t.Error("expected a RemoteError")
+	}
+}
+
+func TestRemoteNilPointer(t *testing.T) {
+	remote := acyclicloader.Components{
+		"Config": func() *config { return nil },
+		"Port":   func() int { return 8080 },
+	}.AsLoader()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go NewServer(remote).Serve(l)
+
+	client, err := Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	loader := acyclicloader.Components{
+		"Config": Remote(client, "Config", &config{}),
+		"Port":   Remote(client, "Port", 0),
+	}.AsLoader()
+
+	_, err = loader.Load("Config")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*RemoteError); !ok || !strings.Contains(err.Error(), "nil pointer") {
+		t.Error("expected a RemoteError for the nil pointer")
+	}
+
+	// The server must still be serving after the failed encode
+	if loader.MustLoad("Port").(int) != 8080 {
+		t.Error("expected 8080")
+	}
+}
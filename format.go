@@ -0,0 +1,88 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// String returns a description of the components in a, one per line, with
+// their type, state and dependencies, such as:
+//   Config main.Config (loaded)
+//   Server *http.Server (unloaded) -> Config, Database
+//
+// Use %+v to format a as a tree of dependencies.
+func (a *AcyclicLoader) String() string {
+	var b strings.Builder
+	for _, s := range a.status() {
+		b.WriteString(describeStatus(s))
+		if len(s.Dependencies) > 0 {
+			fmt.Fprintf(&b, " -> %s", strings.Join(s.Dependencies, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Format implements fmt.Formatter, such that %+v formats a as a tree of
+// components rooted at components no other component depends on, and %v and
+// %s format a as String().
+func (a *AcyclicLoader) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		io.WriteString(f, a.tree())
+	case verb == 'v' || verb == 's':
+		io.WriteString(f, a.String())
+	default:
+		fmt.Fprintf(f, "%%!%c(*acyclicloader.AcyclicLoader)", verb)
+	}
+}
+
+// tree returns the components of a as an ASCII tree, components already shown
+// are not expanded again.
+func (a *AcyclicLoader) tree() string {
+	status := a.status()
+	byName := make(map[string]componentStatus, len(status))
+	dependedOn := map[string]bool{}
+	for _, s := range status {
+		byName[s.Name] = s
+		for _, dep := range s.Dependencies {
+			dependedOn[dep] = true
+		}
+	}
+
+	var b strings.Builder
+	shown := map[string]bool{}
+	var write func(name, prefix, childPrefix string)
+	write = func(name, prefix, childPrefix string) {
+		s := byName[name]
+		b.WriteString(prefix + describeStatus(s))
+		if shown[name] && len(s.Dependencies) > 0 {
+			b.WriteString(" ...\n")
+			return
+		}
+		b.WriteString("\n")
+		shown[name] = true
+		for i, dep := range s.Dependencies {
+			if i == len(s.Dependencies)-1 {
+				write(dep, childPrefix+"└── ", childPrefix+"    ")
+			} else {
+				write(dep, childPrefix+"├── ", childPrefix+"│   ")
+			}
+		}
+	}
+	for _, s := range status {
+		if !dependedOn[s.Name] {
+			write(s.Name, "", "")
+		}
+	}
+	return b.String()
+}
+
+func describeStatus(s componentStatus) string {
+	description := s.Name
+	if s.Type != "" {
+		description += " " + s.Type
+	}
+	return fmt.Sprintf("%s (%s)", description, s.State)
+}
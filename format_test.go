@@ -0,0 +1,64 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	loader := Components{
+		"Port": func() int { return 80 },
+		"Host": func() string { return "localhost" },
+		"Address": func(options struct {
+			Port int
+			Host string
+		}) string {
+			return fmt.Sprintf("%s:%d", options.Host, options.Port)
+		},
+		"Server": func(options struct {
+			Address string
+			Port    int
+		}) error {
+			return nil
+		},
+	}.AsLoader()
+	loader.MustLoad("Address")
+
+	want := "Address string (loaded) -> Host, Port\n" +
+		"Host string (loaded)\n" +
+		"Port int (loaded)\n" +
+		"Server (unloaded) -> Address, Port\n"
+	if got := loader.String(); got != want {
+		t.Errorf("unexpected String():\n%s\nwant:\n%s", got, want)
+	}
+	if got := fmt.Sprintf("%v", loader); got != want {
+		t.Errorf("unexpected %%v:\n%s\nwant:\n%s", got, want)
+	}
+
+	want = "Server (unloaded)\n" +
+		"├── Address string (loaded)\n" +
+		"│   ├── Host string (loaded)\n" +
+		"│   └── Port int (loaded)\n" +
+		"└── Port int (loaded)\n"
+	if got := fmt.Sprintf("%+v", loader); got != want {
+		t.Errorf("unexpected %%+v:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatRepeatedSubtree(t *testing.T) {
+	loader := Components{
+		"Config": func() string { return "" },
+		"DB":     func(options struct{ Config string }) int { return 0 },
+		"A":      func(options struct{ DB int }) bool { return true },
+		"B":      func(options struct{ DB int }) bool { return true },
+	}.AsLoader()
+
+	want := "A bool (unloaded)\n" +
+		"└── DB int (unloaded)\n" +
+		"    └── Config string (unloaded)\n" +
+		"B bool (unloaded)\n" +
+		"└── DB int (unloaded) ...\n"
+	if got := fmt.Sprintf("%+v", loader); got != want {
+		t.Errorf("unexpected %%+v:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package acyclicloader
+
+// Freeze prevents further modification of the AcyclicLoader, such that
+// WithOverwrites(), WithOverwriteFuncs(), Decorate() and Register() return a
+// FrozenError. Loaders derived from a, such as clones, are frozen too.
+//
+// Production binaries can freeze the loader after initialization, to guarantee
+// that the components running are exactly those defined at init.
+func (a *AcyclicLoader) Freeze() {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if a.frozen {
+		return
+	}
+	a.frozen = true
+	a.options = append(a.options[:len(a.options):len(a.options)], func(a *AcyclicLoader) {
+		a.frozen = true
+	})
+}
+
+// checkFrozen returns a FrozenError for operation, if a is frozen, this must
+// be called with a.m locked.
+func (a *AcyclicLoader) checkFrozen(operation string) error {
+	if a.frozen {
+		return &FrozenError{Operation: operation}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package acyclicloader
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	loader := Components{
+		"Port": func() int { return 80 },
+	}.AsLoader()
+	loader.Freeze()
+
+	check := func(operation string, err error) {
+		var e *FrozenError
+		if !errors.As(err, &e) || e.Operation != operation {
+			t.Errorf("expected FrozenError from %s(), got: %v", operation, err)
+		}
+	}
+	_, err := loader.WithOverwrites(map[string]interface{}{"Port": 443})
+	check("WithOverwrites", err)
+	_, err = loader.WithOverwriteFuncs(map[string]interface{}{"Port": func() int { return 443 }})
+	check("WithOverwriteFuncs", err)
+	_, err = loader.Decorate("Port", func(port int) int { return port + 1 })
+	check("Decorate", err)
+	check("Register", loader.Register("Host", func() string { return "localhost" }))
+
+	_, err = loader.Clone().WithOverwrites(map[string]interface{}{"Port": 443})
+	check("WithOverwrites", err)
+	without, err := loader.Without("Port")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = without.WithOverwrites(map[string]interface{}{})
+	check("WithOverwrites", err)
+
+	if loader.MustLoad("Port") != 80 {
+		t.Error("expected frozen loader to load components")
+	}
+}
@@ -0,0 +1,138 @@
+//go:build go1.18
+// +build go1.18
+
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Define adds a component to the Builder, where fn takes a struct of
+// dependencies and returns the component. The result type of the component is
+// checked at compile-time, rather than discovered when the loader is built.
+//
+//   acyclicloader.Define(b, "Server", func(options struct {
+//       Port int
+//   }) (*http.Server, error) {
+//       return &http.Server{Addr: fmt.Sprintf(":%d", options.Port)}, nil
+//   })
+func Define[T, D any](b *Builder, name string, fn func(D) (T, error)) *Builder {
+	b.add(callSite(1), name, fn, false)
+	return b
+}
+
+// Supply adds a component without dependencies to the Builder, the result
+// type of the component is checked at compile-time.
+func Supply[T any](b *Builder, name string, fn func() (T, error)) *Builder {
+	b.add(callSite(1), name, fn, false)
+	return b
+}
+
+// SupplyValue adds a component with a constant value to the Builder, the type
+// of the component is T, even if value is nil or T is an interface type.
+func SupplyValue[T any](b *Builder, name string, value T) *Builder {
+	b.add(callSite(1), name, func() T { return value }, false)
+	return b
+}
+
+// Bind adds a component with interface type I to the Builder, fulfilled by the
+// component implementation. Build() returns an error, if implementation
+// doesn't implement I.
+//
+//   acyclicloader.Bind[Logger](b, "Logger", "ZapLogger")
+func Bind[I any](b *Builder, name, implementation string) *Builder {
+	t := reflect.TypeOf((*I)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		b.errs = append(b.errs, &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"%s: cannot bind '%s' to %s, as it is not an interface type",
+				callSite(1), name, t.String(),
+			),
+		})
+		return b
+	}
+	b.add(callSite(1), name, bindFunc(implementation, t), false)
+	return b
+}
+
+// LoadOf loads the unique component with result type T from the loader, see
+// AcyclicLoader.LoadByType().
+//
+//   db, err := acyclicloader.LoadOf[*sql.DB](loader)
+func LoadOf[T any](a *AcyclicLoader) (T, error) {
+	var result T
+	value, err := a.LoadByType(reflect.TypeOf((*T)(nil)).Elem())
+	if value != nil {
+		result = value.(T)
+	}
+	return result, err
+}
+
+// A Handle refers to a component with type T, such that it can be loaded
+// without type assertions, and renamed with IDE refactoring rather than string
+// search, see Register[T]().
+type Handle[T any] struct {
+	name string
+}
+
+// NewHandle returns a Handle for the named component, which must have type T.
+func NewHandle[T any](name string) Handle[T] {
+	return Handle[T]{name: name}
+}
+
+// Register adds a component with type T to c and returns a Handle for it, fn
+// must be on one of the forms described in Components.
+//
+//   var Database = acyclicloader.Register[*sql.DB](components, "Database", func() (*sql.DB, error) {
+//       return sql.Open("postgres", "...")
+//   })
+//   db, err := Database.Load(loader)
+//
+// This panics, if the component is already defined in c, or fn doesn't return
+// T, as handles are intended to be registered during initialization.
+func Register[T any](c Components, name string, fn interface{}) Handle[T] {
+	if _, ok := c[name]; ok {
+		panic(&ComponentDefinitionError{
+			Component: name,
+			message:   fmt.Sprintf("cannot register '%s' as it is already defined", name),
+		})
+	}
+	component, err := newComponent(name, fn)
+	if err != nil {
+		panic(err)
+	}
+	if t := reflect.TypeOf((*T)(nil)).Elem(); component.result != t {
+		panic(&ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"expected definition of '%s' to return %s, but found %v",
+				name, t.String(), component.result,
+			),
+		})
+	}
+	c[name] = fn
+	return Handle[T]{name: name}
+}
+
+// Name returns the name of the component h refers to.
+func (h Handle[T]) Name() string {
+	return h.name
+}
+
+// Load loads the component h refers to from a.
+func (h Handle[T]) Load(a *AcyclicLoader) (T, error) {
+	var result T
+	err := a.LoadInto(h.name, &result)
+	return result, err
+}
+
+// MustLoad loads the component h refers to from a or panics.
+func (h Handle[T]) MustLoad(a *AcyclicLoader) T {
+	result, err := h.Load(a)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+//go:build go1.18
+// +build go1.18
+
+package acyclicloader
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDefine(t *testing.T) {
+	b := NewBuilder()
+	SupplyValue(b, "Port", 80)
+	SupplyValue[fmt.Stringer](b, "Name", nil)
+	Supply(b, "Host", func() (string, error) { return "localhost", nil })
+	Define(b, "Address", func(options struct {
+		Host string
+		Port int
+	}) (string, error) {
+		return fmt.Sprintf("%s:%d", options.Host, options.Port), nil
+	})
+	loader, err := b.Build()
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Address").(string) != "localhost:80" {
+		t.Error("expected 'localhost:80'")
+	}
+	if loader.MustLoad("Name") != nil {
+		t.Error("expected nil")
+	}
+
+	// Options must still be a struct
+	_, err = Define(NewBuilder(), "Port", func(int) (int, error) {
+		return 80, nil
+	}).Build()
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "generics_test.go:") {
+		t.Error("expected an error with call site")
+	}
+}
+
+func TestBindInterface(t *testing.T) {
+	b := NewBuilder().Add("English", func() *englishGreeter { return &englishGreeter{} })
+	Bind[testGreeter](b, "Greeter", "English")
+	loader, err := b.Build()
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Greeter").(testGreeter).Greet() != "hello" {
+		t.Error("expected 'hello'")
+	}
+
+	// Implementation must implement the interface
+	b = NewBuilder().AddValue("Port", 80)
+	_, err = Bind[testGreeter](b, "Greeter", "Port").Build()
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	// Only interface types can be bound
+	_, err = Bind[int](NewBuilder(), "Greeter", "Port").Build()
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "generics_test.go:") {
+		t.Error("expected an error with call site")
+	}
+}
+
+func TestLoadOf(t *testing.T) {
+	loader := Components{
+		"English": func() *englishGreeter { return &englishGreeter{} },
+		"Greeter": func() testGreeter { return nil },
+	}.AsLoader()
+	english, err := LoadOf[*englishGreeter](loader)
+	if err != nil || english.Greet() != "hello" {
+		t.Error("expected 'hello', got: ", err)
+	}
+	greeter, err := LoadOf[testGreeter](loader)
+	if err != nil || greeter != nil {
+		t.Error("expected nil, got: ", greeter, err)
+	}
+	_, err = LoadOf[int](loader)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestHandle(t *testing.T) {
+	components := Components{}
+	port := Register[int](components, "Port", func() int { return 80 })
+	greeter := Register[testGreeter](components, "Greeter", func() testGreeter {
+		return &englishGreeter{}
+	})
+	loader := components.AsLoader()
+	if port.MustLoad(loader) != 80 || port.Name() != "Port" {
+		t.Error("expected 80")
+	}
+	if g, err := greeter.Load(loader); err != nil || g.Greet() != "hello" {
+		t.Error("expected 'hello', got: ", err)
+	}
+	if _, err := NewHandle[string]("Port").Load(loader); err == nil {
+		t.Error("expected an error for handle with wrong type")
+	}
+
+	defer func() {
+		err := recover()
+		t.Logf("got panic as expected: '%v'", err)
+		if err == nil {
+			t.Error("expected a panic for definition with wrong type")
+		}
+	}()
+	Register[string](components, "Host", func() int { return 80 })
+}
@@ -0,0 +1,86 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// A registry holds components registered with RegisterGlobal().
+type registry struct {
+	m          sync.Mutex
+	components Components
+	packages   map[string]string // package registering each component
+	errs       []error
+}
+
+var globalRegistry = &registry{}
+
+// RegisterGlobal adds a component to the global registry, such that packages
+// can contribute components from init() without a central file importing every
+// constructor, see FromGlobalRegistry().
+//
+//   func init() {
+//       acyclicloader.RegisterGlobal("Database", NewDatabase)
+//   }
+//
+// The component is validated when the loader is created. If name has already
+// been registered, FromGlobalRegistry() returns an error naming the packages
+// registering it.
+func RegisterGlobal(name string, fn interface{}) {
+	globalRegistry.register(name, fn, callerPackage(1))
+}
+
+func (r *registry) register(name string, fn interface{}, pkg string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.components == nil {
+		r.components = Components{}
+		r.packages = map[string]string{}
+	}
+	if previous, ok := r.packages[name]; ok {
+		r.errs = append(r.errs, &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"component '%s' registered by package %s is already registered by package %s",
+				name, pkg, previous,
+			),
+		})
+		return
+	}
+	r.components[name] = fn
+	r.packages[name] = pkg
+}
+
+// FromGlobalRegistry returns a copy of the components registered with
+// RegisterGlobal(), this is usually called from main() after all init()
+// functions have run. A BuildError is returned listing duplicate registrations.
+func FromGlobalRegistry() (Components, error) {
+	r := globalRegistry
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if len(r.errs) > 0 {
+		return nil, &BuildError{Errors: append([]error(nil), r.errs...)}
+	}
+	return r.components.Merge(nil)
+}
+
+// callerPackage returns the import path of the package calling the function
+// skip frames above the caller of callerPackage.
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	fn := runtime.FuncForPC(pc)
+	if !ok || fn == nil {
+		return "unknown"
+	}
+	// Function names have the form path/to/pkg.Func or path/to/pkg.(*T).Method
+	name := fn.Name()
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
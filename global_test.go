@@ -0,0 +1,33 @@
+package acyclicloader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlobalRegistry(t *testing.T) {
+	defer func(r *registry) { globalRegistry = r }(globalRegistry)
+	globalRegistry = &registry{}
+
+	RegisterGlobal("Config", func() string { return "config" })
+	RegisterGlobal("Server", func(options struct{ Config string }) string {
+		return "server with " + options.Config
+	})
+	components, err := FromGlobalRegistry()
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if value := components.AsLoader().MustLoad("Server"); value != "server with config" {
+		t.Error("unexpected value: ", value)
+	}
+
+	RegisterGlobal("Config", func() string { return "other" })
+	_, err = FromGlobalRegistry()
+	if err == nil {
+		t.Fatal("expected an error for duplicate registration")
+	}
+	const pkg = "github.com/jonasfj/go-acyclicloader"
+	if !strings.Contains(err.Error(), "'Config'") || !strings.Contains(err.Error(), "package "+pkg) {
+		t.Error("expected error to name the component and package, got: ", err)
+	}
+}
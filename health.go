@@ -0,0 +1,124 @@
+package acyclicloader
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout is the time allowed for each health check by
+// MetricsHandler() and HealthHandler().
+const healthCheckTimeout = 5 * time.Second
+
+// A componentHealth describes the health of a component, see health().
+type componentHealth struct {
+	Component string `json:"component"`
+	Healthy   bool   `json:"healthy"`
+	State     string `json:"state"` // "healthy", "unhealthy", "failed" or "unloaded"
+	Error     string `json:"error,omitempty"`
+	Critical  bool   `json:"critical"` // see HealthHandler()
+}
+
+// health returns the health of components, sorted by name. Loaded components
+// are checked concurrently like in Supervise(), keyed and transient components
+// are not included, as they don't have a single cached value.
+func (a *AcyclicLoader) health(ctx context.Context) []componentHealth {
+	a.m.Lock()
+	var results []componentHealth
+	var values []interface{}
+	for _, name := range sortedKeys(a.components) {
+		c := a.components[name]
+		if c.keyed || c.transient {
+			continue
+		}
+		a.expire(c)
+		h := componentHealth{Component: name, State: "unloaded"}
+		switch {
+		case c.loaded && c.err != nil:
+			h.State = "failed"
+			h.Error = c.err.Error()
+		case c.loaded:
+			h.State = "healthy"
+			h.Healthy = true
+		}
+		results = append(results, h)
+		values = append(values, c.value)
+	}
+	a.m.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range results {
+		if !results[i].Healthy {
+			continue
+		}
+		wg.Add(1)
+		go func(h *componentHealth, value interface{}) {
+			defer wg.Done()
+			if err := healthCheck(ctx, value, healthCheckTimeout); err != nil {
+				h.Healthy = false
+				h.State = "unhealthy"
+				h.Error = err.Error()
+			}
+		}(&results[i], values[i])
+	}
+	wg.Wait()
+	return results
+}
+
+// HealthHandler returns an http.Handler reporting the health of components as
+// JSON, with status 200 if all critical components are healthy and 503
+// otherwise, such that it can be used for Kubernetes probes:
+//
+//   http.Handle("/healthz", loader.HealthHandler("Database", "Server"))
+//
+// Critical components must be loaded and healthy, while failures of other
+// components are reported without affecting the status. If no components are
+// given, all loaded components are critical. Health is checked on each request
+// like in Supervise(), using the methods Failed() or HealthCheck() if
+// implemented by the value.
+func (a *AcyclicLoader) HealthHandler(critical ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := a.health(r.Context())
+		healthy := true
+		for i := range results {
+			h := &results[i]
+			if len(critical) == 0 {
+				h.Critical = h.State != "unloaded"
+			} else {
+				h.Critical = stringContains(critical, h.Component)
+			}
+			if h.Critical && !h.Healthy {
+				healthy = false
+			}
+		}
+		for _, name := range critical {
+			if !healthContains(results, name) {
+				results = append(results, componentHealth{
+					Component: name,
+					State:     "undefined",
+					Critical:  true,
+				})
+				healthy = false
+			}
+		}
+
+		if !healthy {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		writeJSON(w, struct {
+			Healthy    bool              `json:"healthy"`
+			Components []componentHealth `json:"components"`
+		}{healthy, results})
+	})
+}
+
+func healthContains(results []componentHealth, name string) bool {
+	for _, h := range results {
+		if h.Component == name {
+			return true
+		}
+	}
+	return false
+}
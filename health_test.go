@@ -0,0 +1,54 @@
+package acyclicloader
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthHandler(t *testing.T) {
+	loader := Components{
+		"Database": func() *healthChecked { return &healthChecked{} },
+		"Cache":    func() *healthChecked { return &healthChecked{err: errors.New("timeout")} },
+		"Server":   func() string { return "server" },
+	}.AsLoader()
+	loader.MustLoad("Database")
+	loader.MustLoad("Cache")
+
+	type result struct {
+		Healthy    bool
+		Components []componentHealth
+	}
+	check := func(handler http.Handler) (int, result) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+		var r result
+		if err := json.Unmarshal(w.Body.Bytes(), &r); err != nil {
+			t.Fatal("invalid JSON: ", err)
+		}
+		return w.Code, r
+	}
+
+	code, r := check(loader.HealthHandler("Database"))
+	if code != http.StatusOK || !r.Healthy || len(r.Components) != 3 {
+		t.Error("expected healthy, as 'Cache' isn't critical, got: ", code, r)
+	}
+	for _, h := range r.Components {
+		if h.Component == "Cache" && (h.State != "unhealthy" || h.Error != "timeout" || h.Critical) {
+			t.Error("unexpected health for 'Cache': ", h)
+		}
+	}
+
+	if code, _ := check(loader.HealthHandler()); code != http.StatusServiceUnavailable {
+		t.Error("expected all loaded components to be critical by default, got: ", code)
+	}
+	if code, _ := check(loader.HealthHandler("Database", "Server")); code != http.StatusServiceUnavailable {
+		t.Error("expected unloaded critical components to be unhealthy, got: ", code)
+	}
+	if code, r := check(loader.HealthHandler("Databse")); code != http.StatusServiceUnavailable ||
+		r.Components[len(r.Components)-1].State != "undefined" {
+		t.Error("expected undefined critical components to be unhealthy, got: ", code, r)
+	}
+}
@@ -0,0 +1,77 @@
+package acyclicloader
+
+import (
+	"context"
+	"sync"
+)
+
+// An activity counts work in flight, such as background goroutines loading
+// dependencies and functions loading components. The zero value is idle.
+type activity struct {
+	m     sync.Mutex
+	count int
+	idle  chan struct{} // closed when count drops to zero
+}
+
+func (w *activity) add(delta int) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.count == 0 {
+		w.idle = make(chan struct{})
+	}
+	w.count += delta
+	if w.count == 0 {
+		close(w.idle)
+	}
+}
+
+func (w *activity) wait(ctx context.Context) error {
+	w.m.Lock()
+	if w.count == 0 {
+		w.m.Unlock()
+		return nil
+	}
+	idle := w.idle
+	w.m.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitIdle blocks until no components are being loaded, including dependencies
+// loaded by background goroutines after Load() returned an error for a
+// dependent. This is useful for tests and shutdown, which must not race with
+// background loads.
+//
+// An error is returned, if ctx is done before the loader is idle.
+func (a *AcyclicLoader) WaitIdle(ctx context.Context) error {
+	return a.active.wait(ctx)
+}
+
+// goLoad loads component in a background goroutine tracked by WaitIdle().
+func (a *AcyclicLoader) goLoad(ctx context.Context, component string) {
+	a.active.add(1)
+	go func() {
+		defer a.active.add(-1)
+		a.LoadContext(ctx, component)
+	}()
+}
+
+// goLoadResult loads component in a background goroutine like goLoad(), and
+// returns a channel receiving the error from loading it. The channel is
+// buffered, such that the goroutine finishes even if the error isn't received.
+func (a *AcyclicLoader) goLoadResult(ctx context.Context, component string) <-chan error {
+	result := make(chan error, 1)
+	a.active.add(1)
+	go func() {
+		defer a.active.add(-1)
+		_, err := a.LoadContext(ctx, component)
+		result <- err
+	}()
+	return result
+}
@@ -0,0 +1,54 @@
+package acyclicloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitIdle(t *testing.T) {
+	release := make(chan struct{})
+	loader := Components{
+		"Failing": func() (int, error) { return 0, errors.New("failed") },
+		"Slow": func() int {
+			<-release
+			return 1
+		},
+		"Server": func(options struct {
+			Failing int
+			Slow    int
+		}) int {
+			return 0
+		},
+	}.AsLoader()
+
+	if err := loader.WaitIdle(context.Background()); err != nil {
+		t.Error("expected a new loader to be idle, got: ", err)
+	}
+
+	loaded := make(chan struct{})
+	go func() {
+		defer close(loaded)
+		loader.Load("Server")
+	}()
+	// Wait for 'Failing' to be loaded, while 'Slow' is blocked
+	for !loader.Loaded("Failing") {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := loader.WaitIdle(ctx); err != context.DeadlineExceeded {
+		t.Error("expected WaitIdle to time out while 'Slow' is loading, got: ", err)
+	}
+
+	close(release)
+	if err := loader.WaitIdle(context.Background()); err != nil {
+		t.Error("expected WaitIdle to return, got: ", err)
+	}
+	if !loader.Loaded("Slow") {
+		t.Error("expected 'Slow' to be loaded once idle")
+	}
+	<-loaded
+}
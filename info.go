@@ -0,0 +1,24 @@
+package acyclicloader
+
+import "reflect"
+
+var typeOfInfo = reflect.TypeOf(Info{})
+
+// Info describes the component being loaded. If the options struct for a
+// component has a field of type Info, it is populated with Info for the
+// component, rather than treated as a dependency.
+//
+//   "Metrics": func(options struct {
+//       Info acyclicloader.Info
+//   }) *Metrics {
+//       return NewMetrics(options.Info.Name)
+//   },
+type Info struct {
+	// Name of the component being loaded
+	Name string
+	// Names of the components it depends on
+	Dependencies []string
+	// Metadata given to the loader using WithMetadata(), this must not be
+	// modified
+	Metadata map[string]string
+}
@@ -0,0 +1,161 @@
+package acyclicloader
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ComponentNames returns a sorted list of all components defined in the
+// AcyclicLoader, including components inherited from a parent loader.
+func (a *AcyclicLoader) ComponentNames() []string {
+	a.m.Lock()
+	defer a.m.Unlock()
+	return sortedKeys(a.components)
+}
+
+// Names returns a sorted list of the names of all components in c.
+func (c Components) Names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Loaded returns true, if component has been loaded and its value or error is
+// cached. For keyed components, this returns true if any instance is cached.
+// This returns false for undefined components.
+func (a *AcyclicLoader) Loaded(component string) bool {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	c, ok := a.components[component]
+	if !ok {
+		return false
+	}
+	a.expire(c)
+	return c.loaded || len(c.instances) > 0
+}
+
+// Dependencies returns a sorted list of the components which component
+// depends on directly, including lazy dependencies, feature flags and ordering
+// constraints, see After(). This returns nil for undefined components.
+func (a *AcyclicLoader) Dependencies(component string) []string {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	c, ok := a.components[component]
+	if !ok {
+		return nil
+	}
+	var dependencies []string
+	for _, dep := range c.dependencies {
+		if !stringContains(dependencies, dep) {
+			dependencies = append(dependencies, dep)
+		}
+	}
+	return sortedStrings(dependencies)
+}
+
+// ComponentType returns the type of component, this returns nil for undefined
+// components and components that only return an error.
+func (a *AcyclicLoader) ComponentType(component string) reflect.Type {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	c, ok := a.components[component]
+	if !ok {
+		return nil
+	}
+	return c.result
+}
+
+// LoadDurations returns the time spent in the function loading each loaded
+// component, excluding time spent loading its dependencies. Overwritten values
+// and components inherited from a parent loader are not included.
+func (a *AcyclicLoader) LoadDurations() map[string]time.Duration {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	durations := make(map[string]time.Duration, len(a.components))
+	for name, c := range a.components {
+		if c.loaded && !c.overwritten && !c.inherited {
+			durations[name] = c.duration
+		}
+	}
+	return durations
+}
+
+// TotalLoadDuration returns the wall-clock time from the first of the loaded
+// components started loading, until the last finished loading. As components
+// are loaded concurrently, this is usually less than the sum of LoadDurations().
+func (a *AcyclicLoader) TotalLoadDuration() time.Duration {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	var first, last time.Time
+	for _, c := range a.components {
+		if !c.loaded || c.overwritten || c.inherited {
+			continue
+		}
+		if start := c.loadedAt.Add(-c.duration); first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if last.IsZero() || c.loadedAt.After(last) {
+			last = c.loadedAt
+		}
+	}
+	return last.Sub(first)
+}
+
+// CriticalPath returns the chain of dependencies leading to root, which has the
+// largest sum of load durations, starting with the component loaded first and
+// ending with root. As dependencies are loaded concurrently, this is the chain
+// of components to optimize, in order to reduce the time it takes to load root.
+//
+// Durations are taken from LoadDurations(), components not loaded count as
+// zero, and lazy dependencies are ignored as they don't delay loading. This
+// returns nil, if root isn't defined.
+func (a *AcyclicLoader) CriticalPath(root string) []string {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if _, ok := a.components[root]; !ok {
+		return nil
+	}
+	type step struct {
+		cost time.Duration
+		next string // dependency continuing the path, if any
+	}
+	steps := map[string]step{}
+	var visit func(name string) time.Duration
+	visit = func(name string) time.Duration {
+		if s, ok := steps[name]; ok {
+			return s.cost
+		}
+		c := a.components[name]
+		var s step
+		for i, dep := range c.dependencies {
+			if c.lazy[i] {
+				continue
+			}
+			if cost := visit(dep); s.next == "" || cost > s.cost || (cost == s.cost && dep < s.next) {
+				s = step{cost: cost, next: dep}
+			}
+		}
+		if c.loaded && !c.overwritten && !c.inherited {
+			s.cost += c.duration
+		}
+		steps[name] = s
+		return s.cost
+	}
+	visit(root)
+
+	var path []string
+	for name := root; name != ""; name = steps[name].next {
+		path = append([]string{name}, path...)
+	}
+	return path
+}
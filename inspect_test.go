@@ -0,0 +1,110 @@
+package acyclicloader
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadedAndDependencies(t *testing.T) {
+	loader := Components{
+		"Port": func() int { return 80 },
+		"Host": func() string { return "localhost" },
+		"Address": func(options struct {
+			Port int
+			Host func() (string, error) `component:",lazy"`
+		}) string {
+			return ""
+		},
+	}.AsLoader()
+
+	if loader.Loaded("Port") || loader.Loaded("Undefined") {
+		t.Error("expected nothing to be loaded")
+	}
+	loader.MustLoad("Address")
+	if !loader.Loaded("Port") || !loader.Loaded("Address") || loader.Loaded("Host") {
+		t.Error("expected 'Port' and 'Address' to be loaded, but not 'Host'")
+	}
+
+	deps := loader.Dependencies("Address")
+	if len(deps) != 2 || deps[0] != "Host" || deps[1] != "Port" {
+		t.Error("unexpected dependencies: ", deps)
+	}
+	if loader.Dependencies("Undefined") != nil {
+		t.Error("expected no dependencies for undefined component")
+	}
+}
+
+func TestLoadDurations(t *testing.T) {
+	loader := Components{
+		"Slow": func() int {
+			time.Sleep(20 * time.Millisecond)
+			return 1
+		},
+		"Fast":   func(options struct{ Slow int }) int { return options.Slow },
+		"Unused": func() int { return 0 },
+	}.AsLoader()
+	loader.MustLoad("Fast")
+
+	durations := loader.LoadDurations()
+	if len(durations) != 2 || durations["Slow"] < 20*time.Millisecond || durations["Fast"] >= 20*time.Millisecond {
+		t.Error("unexpected durations: ", durations)
+	}
+	if total := loader.TotalLoadDuration(); total < durations["Slow"]+durations["Fast"] {
+		t.Error("expected total to include both components, got: ", total)
+	}
+	if total := (Components{}).AsLoader().TotalLoadDuration(); total != 0 {
+		t.Error("expected zero duration without components, got: ", total)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	sleep := func(d time.Duration) func() int {
+		return func() int {
+			time.Sleep(d)
+			return 0
+		}
+	}
+	loader := Components{
+		"Config":   sleep(0),
+		"Database": sleep(30 * time.Millisecond),
+		"Cache":    sleep(5 * time.Millisecond),
+		"Repository": func(options struct {
+			Config   int
+			Database int
+		}) int {
+			return 0
+		},
+		"Server": func(options struct {
+			Cache      int
+			Repository int
+			Lazy       func() (int, error) `component:"Slow,lazy"`
+		}) int {
+			return 0
+		},
+		"Slow": sleep(50 * time.Millisecond),
+	}.AsLoader()
+	loader.MustLoad("Server")
+
+	path := loader.CriticalPath("Server")
+	if len(path) != 3 || path[0] != "Database" || path[1] != "Repository" || path[2] != "Server" {
+		t.Error("expected 'Database' -> 'Repository' -> 'Server', got: ", path)
+	}
+	if path := loader.CriticalPath("Undefined"); path != nil {
+		t.Error("expected nil for undefined component, got: ", path)
+	}
+}
+
+func TestComponentNames(t *testing.T) {
+	components := Components{
+		"Port": func() int { return 80 },
+		"Host": func() string { return "localhost" },
+	}
+	if names := components.Names(); len(names) != 2 || names[0] != "Host" || names[1] != "Port" {
+		t.Error("unexpected names: ", names)
+	}
+	parent := components.AsLoader()
+	child, _ := parent.Child(Components{"Address": func() string { return "" }})
+	if names := child.ComponentNames(); len(names) != 3 || names[0] != "Address" || names[2] != "Port" {
+		t.Error("unexpected component names: ", names)
+	}
+}
@@ -0,0 +1,84 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// invokeComponent is the name of the component defined by Invoke() in a child
+// loader, chosen such that it can't collide with a sensible component name.
+const invokeComponent = "<invoke>"
+
+// Invoke calls fn with dependencies loaded from a, fn must be a function on the
+// same form as the functions in Components, and the value it returns, if any,
+// is discarded. This is useful for running tasks, such as a database
+// migration, without defining a component for it.
+//
+//   err := loader.Invoke(func(options struct {
+//       Database *sql.DB
+//       Logger   *log.Logger
+//   }) error {
+//       return migrate(options.Database, options.Logger)
+//   })
+//
+// Definition errors, such as a dependency on an undefined component, are
+// returned as ComponentDefinitionError naming the component "<invoke>", while
+// errors from fn are returned as-is.
+func (a *AcyclicLoader) Invoke(fn interface{}) error {
+	child, err := a.Child(Components{invokeComponent: fn})
+	if err != nil {
+		return err
+	}
+	_, err = child.Load(invokeComponent)
+	return err
+}
+
+// Populate sets the exported fields of the struct target points to, to the
+// components with the same names, as if the struct was the options struct of a
+// component. This is useful in tests, or for handing a set of components to
+// code that isn't aware of the loader.
+//
+//   var deps struct {
+//       Database *sql.DB
+//       Logger   *log.Logger `component:",optional"`
+//   }
+//   err := loader.Populate(&deps)
+//
+// Unexported fields are left as-is, and no fields are set if an error is
+// returned, see Invoke() for errors returned.
+func (a *AcyclicLoader) Populate(target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected target for Populate() to be a pointer to a struct, but found %T", target)
+	}
+
+	// Create an options struct with the exported fields, flattening embedded
+	// structs as the options struct can't embed unexported types.
+	var fields []reflect.StructField
+	walkDependencyFields(v.Elem().Type(), nil, func(field reflect.StructField, _ []int) bool {
+		for _, f := range fields {
+			if f.Name == field.Name {
+				return true
+			}
+		}
+		if field.PkgPath == "" {
+			fields = append(fields, reflect.StructField{
+				Name: field.Name,
+				Type: field.Type,
+				Tag:  field.Tag,
+			})
+		}
+		return true
+	})
+	in := []reflect.Type{reflect.StructOf(fields)}
+	fn := reflect.MakeFunc(reflect.FuncOf(in, nil, false), func(args []reflect.Value) []reflect.Value {
+		walkDependencyFields(v.Elem().Type(), nil, func(field reflect.StructField, index []int) bool {
+			if field.PkgPath == "" {
+				v.Elem().FieldByIndex(index).Set(args[0].FieldByName(field.Name))
+			}
+			return true
+		})
+		return nil
+	})
+	return a.Invoke(fn.Interface())
+}
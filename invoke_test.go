@@ -0,0 +1,68 @@
+package acyclicloader
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInvoke(t *testing.T) {
+	loads := 0
+	loader := Components{
+		"Port": func() int {
+			loads++
+			return 80
+		},
+	}.AsLoader()
+
+	var port int
+	err := loader.Invoke(func(options struct{ Port int }) error {
+		port = options.Port
+		return nil
+	})
+	if err != nil || port != 80 {
+		t.Error("expected 80, got: ", port, err)
+	}
+	loader.Invoke(func(options struct{ Port int }) {})
+	if loads != 1 {
+		t.Error("expected 'Port' to be loaded once")
+	}
+
+	err = loader.Invoke(func() error { return errors.New("failed") })
+	if err == nil || err.Error() != "failed" {
+		t.Error("expected error from fn, got: ", err)
+	}
+
+	err = loader.Invoke(func(options struct{ Prot int }) {})
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*ComponentDefinitionError); !ok {
+		t.Error("expected a ComponentDefinitionError")
+	}
+}
+
+func TestPopulate(t *testing.T) {
+	loader := Components{
+		"Prefix": func() string { return "<" },
+		"Suffix": func() string { return ">" },
+		"Port":   func() int { return 80 },
+	}.AsLoader()
+
+	var target struct {
+		commonDeps
+		Port    int
+		Debug   bool `component:",optional"`
+		private string
+	}
+	target.private = "kept"
+	if err := loader.Populate(&target); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if target.Prefix != "<" || target.Suffix != ">" || target.Port != 80 || target.private != "kept" {
+		t.Error("expected exported fields to be populated, got: ", target)
+	}
+
+	err := loader.Populate(target)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for non-pointer target")
+	}
+}
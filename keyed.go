@@ -0,0 +1,97 @@
+package acyclicloader
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// LoadKeyed loads the named keyed component for the given key, the component is
+// loaded once for each key, see Keyed().
+//
+//   db, err := loader.LoadKeyed("TenantDB", "acme")
+func (a *AcyclicLoader) LoadKeyed(component, key string) (interface{}, error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	c, ok := a.components[component]
+	if !ok {
+		names := sortedKeys(a.components)
+		return nil, &UndefinedComponentError{
+			Component:   component,
+			Suggestions: suggestNames(component, names),
+			Available:   names,
+		}
+	}
+	if !c.keyed {
+		return nil, &KeyedComponentError{Component: component}
+	}
+	return a.load(context.Background(), fmt.Sprintf("%s[%s]", component, key), c.instance(key))
+}
+
+// instance returns the instance of keyed component c for key, creating it if
+// necessary, this must be called with the lock of the loader owning c.
+func (c *component) instance(key string) *component {
+	if instance, ok := c.instances[key]; ok {
+		return instance
+	}
+
+	// Bind the key to the first parameter of the function loading c
+	t := c.fn.Type()
+	in := make([]reflect.Type, t.NumIn()-1)
+	for i := range in {
+		in[i] = t.In(i + 1)
+	}
+	out := make([]reflect.Type, t.NumOut())
+	for i := range out {
+		out[i] = t.Out(i)
+	}
+	k := reflect.ValueOf(key).Convert(t.In(0))
+	fn := c.fn
+	d := *c.definition
+	d.fn = reflect.MakeFunc(reflect.FuncOf(in, out, false), func(args []reflect.Value) []reflect.Value {
+		return fn.Call(append([]reflect.Value{k}, args...))
+	})
+	d.keyed = false
+	instance := &component{definition: &d, forbidden: c.forbidden, overwritten: c.overwritten}
+	if c.overwritten {
+		// The value given to WithOverwrites() is used for every key
+		instance.value = c.value
+		instance.loaded = true
+		instance.loading = true
+	}
+
+	if c.instances == nil {
+		c.instances = map[string]*component{}
+	}
+	c.instances[key] = instance
+	return instance
+}
+
+// keyedLoadFuncType returns the type of a function loading a keyed component
+// with the given result type, on the form func(string) (ComponentType, error).
+func keyedLoadFuncType(result reflect.Type) reflect.Type {
+	return reflect.FuncOf([]reflect.Type{reflect.TypeOf("")}, []reflect.Type{result, typeOfError}, false)
+}
+
+// isKeyedLoadFuncFor returns true, if t is a function type on the form
+// func(string) (T, error), where result is assignable to T.
+func isKeyedLoadFuncFor(t, result reflect.Type) bool {
+	return result != nil && t.Kind() == reflect.Func && t.NumIn() == 1 &&
+		t.In(0).Kind() == reflect.String && t.NumOut() == 2 && t.Out(1) == typeOfError &&
+		result.AssignableTo(t.Out(0))
+}
+
+// keyedLoadFunc returns a function of type t, on the form func(string) (T, error)
+// that loads the named keyed component from a.
+func (a *AcyclicLoader) keyedLoadFunc(name string, t reflect.Type) reflect.Value {
+	result := t.Out(0)
+	return reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+		value, err := a.LoadKeyed(name, args[0].String())
+		errValue := reflect.Zero(typeOfError)
+		if err != nil {
+			errValue = reflect.ValueOf(&err).Elem()
+		}
+		return []reflect.Value{valueOf(result, value), errValue}
+	})
+}
@@ -0,0 +1,137 @@
+package acyclicloader
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestLoadKeyed(t *testing.T) {
+	var connects int32
+	var closed []string
+	loader := Components{
+		"Host": func() string { return "db.example.com" },
+		"TenantDB": Annotate(func(tenant string, options struct{ Host string }) *testCloser {
+			atomic.AddInt32(&connects, 1)
+			return &testCloser{name: options.Host + "/" + tenant, closed: &closed}
+		}, Keyed()),
+		"Service": func(options struct {
+			TenantDB func(string) (*testCloser, error)
+		}) func(string) (*testCloser, error) {
+			return options.TenantDB
+		},
+	}.AsLoader()
+
+	acme, err := loader.LoadKeyed("TenantDB", "acme")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if acme.(*testCloser).name != "db.example.com/acme" {
+		t.Error("expected 'db.example.com/acme', got: ", acme.(*testCloser).name)
+	}
+	again, _ := loader.LoadKeyed("TenantDB", "acme")
+	other, _ := loader.LoadKeyed("TenantDB", "globex")
+	if again != acme || other == acme || connects != 2 {
+		t.Error("expected one value per key")
+	}
+
+	getDB := loader.MustLoad("Service").(func(string) (*testCloser, error))
+	if db, err := getDB("acme"); err != nil || db != acme {
+		t.Error("expected getter to return the cached value, got: ", err)
+	}
+
+	if err := loader.Shutdown(); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(closed) != 2 {
+		t.Error("expected each instance to be closed, got: ", closed)
+	}
+
+	_, err = loader.Load("TenantDB")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*KeyedComponentError); !ok {
+		t.Error("expected a KeyedComponentError")
+	}
+	_, err = loader.LoadKeyed("Host", "acme")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*KeyedComponentError); !ok {
+		t.Error("expected a KeyedComponentError")
+	}
+}
+
+func TestKeyedDefinitionErrors(t *testing.T) {
+	_, err := New(Components{
+		"TenantDB": Annotate(func(options struct{}) int { return 0 }, Keyed()),
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for missing key parameter")
+	}
+
+	_, err = New(Components{
+		"TenantDB": Annotate(func(tenant string) int { return 0 }, Keyed()),
+		"Service":  func(options struct{ TenantDB int }) int { return options.TenantDB },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for depending on keyed component without getter")
+	}
+}
+
+func TestLoadKeyedChild(t *testing.T) {
+	parent := Components{
+		"TenantDB": Annotate(func(tenant string) string {
+			return "db/" + tenant
+		}, Keyed()),
+	}.AsLoader()
+	child, err := parent.Child(Components{
+		"Service": func(options struct {
+			TenantDB func(string) (string, error)
+		}) string {
+			db, _ := options.TenantDB("acme")
+			return db
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if child.MustLoad("Service").(string) != "db/acme" {
+		t.Error("expected 'db/acme'")
+	}
+}
+
+func TestLoadKeyedOverwritten(t *testing.T) {
+	var connects int32
+	loader := Components{
+		"TenantDB": Annotate(func(tenant string) *testCloser {
+			atomic.AddInt32(&connects, 1)
+			return &testCloser{name: tenant}
+		}, Keyed()),
+		"Service": func(options struct {
+			TenantDB func(string) (*testCloser, error)
+		}) func(string) (*testCloser, error) {
+			return options.TenantDB
+		},
+	}.AsLoader()
+
+	mock := &testCloser{name: "mock"}
+	strict, err := loader.Strict("TenantDB")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	for _, l := range []*AcyclicLoader{loader, strict} {
+		overwritten, err := l.WithOverwrites(map[string]interface{}{"TenantDB": mock})
+		if err != nil {
+			t.Fatal("unexpected error: ", err)
+		}
+		if db, err := overwritten.LoadKeyed("TenantDB", "acme"); err != nil || db != mock {
+			t.Error("expected the overwritten value, got: ", db, err)
+		}
+		getter := overwritten.MustLoad("Service").(func(string) (*testCloser, error))
+		if db, err := getter("globex"); err != nil || db != mock {
+			t.Error("expected the overwritten value from the getter, got: ", db, err)
+		}
+	}
+	if n := atomic.LoadInt32(&connects); n != 0 {
+		t.Error("expected 'TenantDB' not to be loaded, got: ", n)
+	}
+}
@@ -0,0 +1,35 @@
+package acyclicloader
+
+import "reflect"
+
+// loadFuncType returns the type of a function loading a component with the
+// given result type, on the form func() (ComponentType, error) or func() error.
+func loadFuncType(result reflect.Type) reflect.Type {
+	if result == nil {
+		return reflect.FuncOf(nil, []reflect.Type{typeOfError}, false)
+	}
+	return reflect.FuncOf(nil, []reflect.Type{result, typeOfError}, false)
+}
+
+// isLoadFuncFor returns true, if t is a function type on the form
+// func() (T, error), where result is assignable to T.
+func isLoadFuncFor(t, result reflect.Type) bool {
+	return result != nil && t.Kind() == reflect.Func && t.NumIn() == 0 &&
+		t.NumOut() == 2 && t.Out(1) == typeOfError && result.AssignableTo(t.Out(0))
+}
+
+// loadFunc returns a function of type loadFuncType(result) that loads the
+// named component from a.
+func (a *AcyclicLoader) loadFunc(name string, result reflect.Type) reflect.Value {
+	return reflect.MakeFunc(loadFuncType(result), func([]reflect.Value) []reflect.Value {
+		value, err := a.Load(name)
+		errValue := reflect.Zero(typeOfError)
+		if err != nil {
+			errValue = reflect.ValueOf(&err).Elem()
+		}
+		if result == nil {
+			return []reflect.Value{errValue}
+		}
+		return []reflect.Value{valueOf(result, value), errValue}
+	})
+}
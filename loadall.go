@@ -0,0 +1,150 @@
+package acyclicloader
+
+import (
+	"context"
+	"time"
+)
+
+type loadAllOptions struct {
+	progress func(done, total int, current string)
+	budget   time.Duration
+}
+
+// A LoadAllOption configures AcyclicLoader.LoadAll().
+type LoadAllOption func(*loadAllOptions)
+
+// ReportProgress returns a LoadAllOption that calls progress as components
+// finish loading, such that tools can show progress while a long startup is
+// in progress:
+//
+//   err := loader.LoadAll(ctx, acyclicloader.ReportProgress(func(done, total int, current string) {
+//       fmt.Printf("\rLoading %d/%d: %s", done, total, current)
+//   }))
+//
+// Components are reported such that dependencies come before dependents, with
+// current being the component waited for, after all components are loaded
+// progress is called with done equal to total and current empty.
+func ReportProgress(progress func(done, total int, current string)) LoadAllOption {
+	return func(o *loadAllOptions) {
+		o.progress = progress
+	}
+}
+
+// StartupBudget returns a LoadAllOption that makes LoadAll() return a
+// StartupBudgetError, if all components aren't loaded within d. The error lists
+// the components finished, in flight and not started, such that a slow startup
+// can be diagnosed before an orchestrator kills the process.
+func StartupBudget(d time.Duration) LoadAllOption {
+	return func(o *loadAllOptions) {
+		o.budget = d
+	}
+}
+
+// LoadAll loads all components concurrently, keyed and transient components
+// are not loaded, as they are loaded on demand.
+//
+// All components are loaded even if some fail, the first error is returned,
+// ignoring components disabled by feature flags. If ctx is done or the startup
+// budget expires first, an error is returned while components continue loading
+// in the background, see WaitIdle().
+func (a *AcyclicLoader) LoadAll(ctx context.Context, options ...LoadAllOption) error {
+	var o loadAllOptions
+	for _, option := range options {
+		option(&o)
+	}
+	progress := o.progress
+	if progress == nil {
+		progress = func(int, int, string) {}
+	}
+	// The budget isn't applied to ctx, as it is given to components, which may
+	// use it after LoadAll() returns
+	var expired <-chan time.Time
+	if o.budget > 0 {
+		timer := time.NewTimer(o.budget)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	// Order components such that dependencies come before dependents
+	a.m.Lock()
+	var order []string
+	visited := make(map[string]bool, len(a.components))
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		c := a.components[name]
+		for _, dep := range c.dependencies {
+			visit(dep)
+		}
+		if !c.keyed && !c.transient {
+			order = append(order, name)
+		}
+	}
+	for _, name := range sortedKeys(a.components) {
+		visit(name)
+	}
+	a.m.Unlock()
+
+	results := make([]<-chan error, len(order))
+	for i, name := range order {
+		results[i] = a.goLoadResult(ctx, name)
+	}
+	var err error
+	for done, name := range order {
+		progress(done, len(order), name)
+		var loadErr error
+		select {
+		case loadErr = <-results[done]:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-expired:
+			return a.startupBudgetError(o.budget, order)
+		}
+		if _, disabled := loadErr.(*DisabledComponentError); err == nil && !disabled {
+			err = loadErr
+		}
+	}
+	progress(len(order), len(order), "")
+	return err
+}
+
+// LoadAllWithProgress loads all components like LoadAll(), calling progress as
+// components finish loading, see ReportProgress().
+func (a *AcyclicLoader) LoadAllWithProgress(ctx context.Context, progress func(done, total int, current string)) error {
+	return a.LoadAll(ctx, ReportProgress(progress))
+}
+
+// startupBudgetError returns a StartupBudgetError describing the state of the
+// components in order.
+func (a *AcyclicLoader) startupBudgetError(budget time.Duration, order []string) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	// Components waiting for dependencies are marked loading too, so those in
+	// flight are found by the goroutines calling the functions loading them
+	inFlight := map[string]bool{}
+	for _, g := range a.constructing {
+		for _, name := range g.components {
+			inFlight[name] = true
+		}
+	}
+	e := &StartupBudgetError{Budget: budget}
+	for _, name := range order {
+		c := a.components[name]
+		switch {
+		case c.loaded:
+			e.Finished = append(e.Finished, name)
+		case inFlight[name]:
+			e.InFlight = append(e.InFlight, name)
+		default:
+			e.NotStarted = append(e.NotStarted, name)
+		}
+	}
+	e.Finished = sortedStrings(e.Finished)
+	e.InFlight = sortedStrings(e.InFlight)
+	e.NotStarted = sortedStrings(e.NotStarted)
+	return e
+}
@@ -0,0 +1,118 @@
+package acyclicloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadAllWithProgress(t *testing.T) {
+	failure := errors.New("connection refused")
+	loader := Components{
+		"Config":   func() string { return "config" },
+		"Database": func(options struct{ Config string }) (int, error) { return 0, failure },
+		"Server":   func(options struct{ Config string }) string { return "server" },
+		"Flag":     func() bool { return false },
+		"Feature":  Annotate(func() int { return 1 }, EnabledBy("Flag")),
+		"Worker":   Annotate(func() int { return 1 }, Transient()),
+	}.AsLoader()
+
+	var reports []string
+	err := loader.LoadAllWithProgress(context.Background(), func(done, total int, current string) {
+		reports = append(reports, fmt.Sprintf("%d/%d: %s", done, total, current))
+	})
+	if !errors.Is(err, failure) {
+		t.Error("expected the error from 'Database', got: ", err)
+	}
+	expected := []string{
+		"0/5: Config", "1/5: Database", "2/5: Flag", "3/5: Feature", "4/5: Server", "5/5: ",
+	}
+	if fmt.Sprint(reports) != fmt.Sprint(expected) {
+		t.Error("unexpected progress: ", reports)
+	}
+	if !loader.Loaded("Server") || loader.Loaded("Worker") {
+		t.Error("expected all components except transient ones to be loaded")
+	}
+}
+
+func TestLoadAllLoadsOnce(t *testing.T) {
+	var m sync.Mutex
+	loads := map[string]int{}
+	count := func(name string) string {
+		time.Sleep(time.Millisecond) // give other goroutines a chance to load it
+		m.Lock()
+		defer m.Unlock()
+		loads[name]++
+		return name
+	}
+	loader := Components{
+		"Base": func() string { return count("Base") },
+		"Mid":  func(options struct{ Base string }) string { return count("Mid") },
+		"TopA": func(options struct{ Mid string }) string { return count("TopA") },
+		"TopB": func(options struct{ Mid string }) string { return count("TopB") },
+	}.AsLoader()
+
+	if err := loader.LoadAll(context.Background()); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	m.Lock()
+	defer m.Unlock()
+	for _, name := range []string{"Base", "Mid", "TopA", "TopB"} {
+		if loads[name] != 1 {
+			t.Error("expected each component to be loaded once, got: ", loads)
+			break
+		}
+	}
+}
+
+func TestLoadAllWithProgressContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	loader := Components{
+		"Slow": func() int {
+			<-release
+			return 1
+		},
+	}.AsLoader()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := loader.LoadAllWithProgress(ctx, nil); err != context.DeadlineExceeded {
+		t.Error("expected the context to time out, got: ", err)
+	}
+}
+
+func TestStartupBudget(t *testing.T) {
+	release := make(chan struct{})
+	loader := Components{
+		"Config": func() string { return "config" },
+		"Database": func(options struct{ Config string }) string {
+			<-release
+			return "database"
+		},
+		"Server": func(options struct{ Database string }) string { return "server" },
+	}.AsLoader()
+
+	err := loader.LoadAll(context.Background(), StartupBudget(20*time.Millisecond))
+	e, ok := err.(*StartupBudgetError)
+	if !ok {
+		t.Fatal("expected a StartupBudgetError, got: ", err)
+	}
+	if fmt.Sprint(e.Finished, e.InFlight, e.NotStarted) != "[Config] [Database] [Server]" {
+		t.Error("unexpected components: ", e.Finished, e.InFlight, e.NotStarted)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected errors.Is() to match context.DeadlineExceeded")
+	}
+
+	// Loading continues in the background, tracked by WaitIdle()
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := loader.WaitIdle(ctx); err != nil || !loader.Loaded("Server") {
+		t.Error("expected 'Server' to be loaded in the background, got: ", err)
+	}
+}
@@ -1,31 +1,119 @@
 package acyclicloader
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"runtime/debug"
+	"runtime/pprof"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 var typeOfError = reflect.TypeOf((*error)(nil)).Elem()
+var typeOfContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+var typeOfBool = reflect.TypeOf(false)
+var typeOfDuration = reflect.TypeOf(time.Duration(0))
 
 // An AcyclicLoader holds functions for loading components with acyclic
 // dependencies with maximum concurrency.
 type AcyclicLoader struct {
-	m          sync.Mutex
-	c          sync.Cond
-	components map[string]*component
+	m           sync.Mutex
+	c           sync.Cond
+	components  map[string]*component
+	definitions Components
+	options     []Option
+	parent      *AcyclicLoader
+	generation  uint64 // incremented when cached values are purged
+	// generation of parent when inherited values were last checked
+	parentGeneration uint64
+	logger           Logger
+	strict           bool
+	autoWire         bool
+	profile          string
+	middleware       []Middleware
+	recorders        []*Recorder
+	events           []chan Event // see Events()
+	purged           []string     // components purged when this loader was created
+	gracePeriod      time.Duration
+	backoff          Backoff // used by Supervise(), if not nil
+	roots            []string
+	slow             time.Duration            // see WithSlowThreshold()
+	semaphores       map[string]chan struct{} // see WithSemaphore()
+	scheduler        *scheduler               // see WithMaxConcurrency()
+	rejectNil        bool                     // see WithNilResultsAsErrors()
+	frozen           bool
+	metadata         map[string]string
+	// components being loaded by each goroutine, indexed by goroutine id
+	constructing map[int64]*goroutineLoads
+	active       activity // see WaitIdle()
 }
 
+// A component holds the state of a component in an AcyclicLoader, the
+// definition is immutable once the loader has been created, and is shared
+// between clones of the loader.
 type component struct {
-	fn           reflect.Value
-	result       reflect.Type
-	dependencies []string
-	value        interface{}
-	err          error
-	loaded       bool
-	loading      bool
+	*definition
+	instances   map[string]*component // instances of a keyed component
+	forbidden   bool                  // true, if loading fails unless overwritten
+	failures    int                   // number of consecutive failures to load
+	failedDeps  []string              // dependencies that caused err, if any
+	overwritten bool
+	value       interface{}
+	err         error
+	loaded      bool
+	loadedAt    time.Time
+	duration    time.Duration // time spent calling the function loading c
+	version     string        // version of the value when it was loaded, see Version()
+	loading     bool
+	queued      int // number of calls to load() in progress, see blockedBy()
+}
+
+// A definition holds everything about a component, that doesn't change when
+// it is loaded.
+type definition struct {
+	fn               reflect.Value
+	result           reflect.Type
+	dependencies     []string
+	fields           [][]int
+	lazy             []bool
+	grouped          []bool
+	optional         []bool
+	infoFields       [][]int
+	contextFields    [][]int
+	notGoroutineSafe bool
+	serialize        *sync.Mutex // shared by clones, as they share cached values
+	acknowledged     []string
+	renamed          map[string]string
+	enabledBy        string
+	ttl              time.Duration
+	watched          []string
+	transient        bool
+	scoped           bool
+	keyed            bool
+	pool             int          // number of instances to load, if pooled
+	element          reflect.Type // type of instances, if pooled
+	tags             []string
+	inherited        bool
+	threshold        int     // failures before the circuit opens, if any
+	cooldown         Backoff // duration the circuit stays open
+	output           string  // component returning a struct embedding Out, if a field of it
+	paramsByType     bool
+	versionFunc      func() string
+	semaphore        string // see LimitedBy()
+	priority         int
+	after            []string // components to load before this, see After()
+	defaults         []fieldDefault
+	allowNil         bool // see AllowNil()
+}
+
+// A fieldDefault is the value of a `default:"..."` tag, for an optional
+// dependency that isn't defined.
+type fieldDefault struct {
+	index []int
+	value reflect.Value
 }
 
 // Components holds a set of components with acyclic inter-dependencies.
@@ -36,20 +124,81 @@ type component struct {
 //   func () (ComponentType, error)
 //   func (struct{Dependency DependencyType, ...}) ComponentType
 //   func (struct{Dependency DependencyType, ...}) (ComponentType, error)
+//   func (*struct{Dependency DependencyType, ...}) (ComponentType, error)
 // where ComponentType is the type of the component, and Dendency is a component
 // that this component depends on and DependencyType is the type of said
-// dependency.
+// dependency, or a type said dependency is assignable to, such as an interface
+// implemented by the dependency.
 //
 // For example, the following "Users" component has type *UserModel and depends
 // on the "Database" component which has the type *sql.DB.
 //   "Users": func(options struct { Database *sql.DB }) *UserModel {
 //       return &UserModel{db: options.Database}
 //   },
+//
+// By default the field name is the name of the component depended on, this
+// can be overwritten with a struct tag, such that a field can depend on a
+// component with a name that isn't a valid Go identifier. The struct tag can
+// also declare a dependency optional, such that the field is left as zero value
+// if the component isn't defined, while a defined component is loaded as any
+// other dependency. This allows a shared set of components to use integrations
+// only if the host defines them. An optional dependency may have a default tag,
+// such as `default:"8080"`, in which case the field is set to the parsed value
+// when the component isn't defined. Fields of embedded structs are also treated as
+// dependencies, allowing common dependencies to be declared in a shared struct.
+// A field of type context.Context is populated with the context given to
+// LoadContext(), rather than treated as a dependency, see also Info.
+//
+// A dependency can be declared lazy with a struct tag, in which case the field
+// must have the type func() (DependencyType, error). The dependency isn't
+// loaded before the component, instead it is loaded when the function is
+// called, this is useful for heavy dependencies that are rarely used.
+//   "Reports": func(options struct {
+//       Renderer func() (*PDFRenderer, error) `component:",lazy"`
+//   }) *ReportService {
+//       return &ReportService{renderer: options.Renderer}
+//   },
+//   "Users": func(options struct {
+//       DB     *sql.DB     `component:"read-replica"`
+//       Tracer *log.Logger `component:",optional"`
+//   }) *UserModel {
+//       return &UserModel{db: options.DB}
+//   },
+//
+// A slice field can be declared a group with a struct tag, in which case it is
+// populated with all other components assignable to the element type of the
+// slice, in alphabetical order of component names. Group members are found
+// when the loader is created, so components added with Register() don't join
+// existing groups.
+//   "Health": func(options struct {
+//       Checks []HealthChecker `component:",group"`
+//   }) *HealthService {
+//       return &HealthService{checks: options.Checks}
+//   },
+//
+// A function may also take plain parameters instead of an options struct, in
+// which case each parameter is wired to the unique component assignable to its
+// type, New() returns an error if there isn't exactly one such component. The
+// same applies to fields of an options struct tagged with `component:",bytype"`.
+// A single parameter that is a struct or pointer to a struct is always treated
+// as an options struct.
+//   "Users": func(db *sql.DB, logger *log.Logger) *UserModel {
+//       return &UserModel{db: db, logger: logger}
+//   },
+//
+// The function may also be wrapped with Annotate() to modify how the component
+// is loaded.
+//
+// A value that isn't a function defines a constant component, with the type of
+// the value. To define a component holding a function, such as a handler, it
+// must be returned from a function.
+//   "Port":      80,
+//   "BuildInfo": info,
 type Components map[string]interface{}
 
 // AsLoader returns an AcyclicLoader or panics
-func (c Components) AsLoader() *AcyclicLoader {
-	a, err := New(c)
+func (c Components) AsLoader(options ...Option) *AcyclicLoader {
+	a, err := New(c, options...)
 	if err != nil {
 		panic(err)
 	}
@@ -71,206 +220,972 @@ func (c Components) MustLoad(component string) interface{} {
 // missing dependency in the set of components given. Since such an error is
 // consistent it is preferable to use acyclicloader.Components{...}.AsLoader()
 // when creating a loader as global variable.
-func New(components Components) (*AcyclicLoader, error) {
+func New(components Components, options ...Option) (*AcyclicLoader, error) {
 	a := &AcyclicLoader{
-		components: make(map[string]*component, len(components)),
+		components:  make(map[string]*component, len(components)),
+		definitions: make(Components, len(components)),
+		options:     options,
+		logger:      defaultLogger,
 	}
 	a.c.L = &a.m
+	for _, option := range options {
+		option(a)
+	}
+
+	// Select definitions for the profile, components without a definition for
+	// the profile are left undefined
+	for name, fn := range components {
+		if p, ok := fn.(*profiled); ok {
+			if fn = p.selectProfile(a.profile); fn == nil {
+				continue
+			}
+		}
+		a.definitions[name] = fn
+	}
 
 	// Sort component names so that the error returned is always the same
 	// otherwise it gets really confusing to debug
-	componentNames := make([]string, 0, len(components))
-	for name := range components {
+	componentNames := make([]string, 0, len(a.definitions))
+	for name := range a.definitions {
 		componentNames = append(componentNames, name)
 	}
 	sort.Strings(componentNames)
 
 	// Populate components
 	for _, name := range componentNames {
-		fn := components[name]
-		if fn == nil {
-			return nil, &ComponentDefinitionError{
-				Component: name,
-				message:   fmt.Sprintf("expected definition of '%s' to be a function, but found nil", name),
-			}
+		c, err := newComponent(name, a.definitions[name])
+		if err != nil {
+			return nil, err
 		}
-		t := reflect.TypeOf(fn)
-		if t.Kind() != reflect.Func {
-			return nil, &ComponentDefinitionError{
-				Component: name,
-				message: fmt.Sprintf(
-					"expected definition of '%s' to be a function, but found %s",
-					name, t.String(),
-				),
-			}
-		}
-		var result reflect.Type
-		switch t.NumOut() {
-		case 0:
-		case 1:
-			if t.Out(0) != typeOfError {
-				result = t.Out(0)
+		a.components[name] = c
+	}
+
+	// Add components for fields of results embedding Out
+	outputs, err := a.addOutputs(componentNames)
+	if err != nil {
+		return nil, err
+	}
+	componentNames = append(componentNames, outputs...)
+	sort.Strings(componentNames)
+
+	// Inherit components from parent, if not defined in this loader
+	if a.parent != nil {
+		a.parent.m.Lock()
+		for _, name := range sortedKeys(a.parent.components) {
+			if _, ok := a.components[name]; ok {
+				continue
 			}
-		case 2:
-			result = t.Out(0)
-			if t.Out(1) != typeOfError {
-				return nil, &ComponentDefinitionError{
-					Component: name,
-					message: fmt.Sprintf(
-						"expected 2nd result from '%s' to have error type, but found %s",
-						name, t.Out(1).String(),
-					),
+			if a.parent.components[name].scoped {
+				// Scoped components are loaded again in each child loader
+				fn := a.parent.definitions[name]
+				c, err := newComponent(name, fn)
+				if err != nil {
+					a.parent.m.Unlock()
+					return nil, err
 				}
+				a.components[name] = c
+				a.definitions[name] = fn
+			} else {
+				a.components[name] = a.parent.inherit(name)
 			}
-		default:
+			componentNames = append(componentNames, name)
+		}
+		a.parent.m.Unlock()
+		sort.Strings(componentNames)
+	}
+
+	// Populate and check dependencies
+	for _, name := range componentNames {
+		if err := a.resolveDependencies(name, componentNames); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check for cycles, reporting all of them at once
+	if cycles := a.detectCycles(); len(cycles) > 0 {
+		descriptions := make([]string, len(cycles))
+		for i, cycle := range cycles {
+			descriptions[i] = fmt.Sprintf("'%s'", strings.Join(cycle, "' -> '"))
+		}
+		message := "dependency cycle detected: " + descriptions[0]
+		if len(cycles) > 1 {
+			message = fmt.Sprintf(
+				"%d dependency cycles detected: %s",
+				len(cycles), strings.Join(descriptions, ", "),
+			)
+		}
+		return nil, &ComponentDefinitionError{
+			Component: cycles[0][0],
+			message:   message,
+		}
+	}
+
+	// Check for concurrent use of components that aren't goroutine-safe
+	for _, name := range componentNames {
+		if err := a.warn(a.checkGoroutineSafety(name)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(a.roots) > 0 {
+		if err := a.checkRoots(); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// newComponent returns a component from the definition fn, without resolving
+// dependencies.
+func newComponent(name string, fn interface{}) (*component, error) {
+	var annotations []Annotation
+	if an, ok := fn.(*Annotated); ok {
+		fn = an.fn
+		annotations = an.annotations
+	}
+	if fn == nil {
+		return nil, &ComponentDefinitionError{
+			Component: name,
+			message:   fmt.Sprintf("expected definition of '%s' to be a function or value, but found nil", name),
+		}
+	}
+	t := reflect.TypeOf(fn)
+	if t.Kind() != reflect.Func {
+		fn = valueFunc(fn)
+		t = reflect.TypeOf(fn)
+	}
+	var result reflect.Type
+	switch t.NumOut() {
+	case 0:
+	case 1:
+		if t.Out(0) != typeOfError {
+			result = t.Out(0)
+		}
+	case 2:
+		result = t.Out(0)
+		if t.Out(1) != typeOfError {
 			return nil, &ComponentDefinitionError{
 				Component: name,
 				message: fmt.Sprintf(
-					"expected no more than 2 results from '%s', but found %d outputs",
-					name, t.NumOut(),
+					"expected 2nd result from '%s' to have error type, but found %s",
+					name, t.Out(1).String(),
 				),
 			}
 		}
-		a.components[name] = &component{
-			fn:     reflect.ValueOf(fn),
-			result: result,
+	default:
+		return nil, &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"expected no more than 2 results from '%s', but found %d outputs",
+				name, t.NumOut(),
+			),
 		}
 	}
+	c := &component{definition: &definition{
+		fn:     reflect.ValueOf(fn),
+		result: result,
+	}}
+	for _, annotate := range annotations {
+		annotate(c)
+	}
+	skip := 0
+	if c.keyed {
+		skip = 1
+	}
+	if t.NumIn() > skip && ((c.paramsByType && !t.IsVariadic()) || hasPlainParams(t, skip)) {
+		c.fn = wireByType(c.fn, skip)
+	}
+	return c, nil
+}
 
-	// Populate and check dependencies
-	for _, name := range componentNames {
-		component := a.components[name]
-		t := component.fn.Type()
-		switch t.NumIn() {
-		case 0:
-			continue // dependencies = nil
-		case 1:
-			// We continue below
-		default:
-			return nil, &ComponentDefinitionError{
+// copy returns a copy of c for use in another loader, where c isn't being
+// loaded, this must be called with the lock of the loader owning c.
+func (c *component) copy() *component {
+	c2 := &component{}
+	c.copyTo(c2)
+	return c2
+}
+
+// copyTo copies c into c2 sharing the definition, see copy().
+func (c *component) copyTo(c2 *component) {
+	*c2 = *c
+	c2.loading = c.loaded
+	c2.queued = 0
+	if c.instances != nil {
+		c2.instances = make(map[string]*component, len(c.instances))
+		for key, instance := range c.instances {
+			c2.instances[key] = instance.copy()
+		}
+	}
+}
+
+// copyComponents returns copies of components for use in another loader, the
+// copies are allocated together, as loaders are often cloned in tests.
+func copyComponents(components map[string]*component) map[string]*component {
+	copies := make([]component, len(components))
+	result := make(map[string]*component, len(components))
+	i := 0
+	for name, c := range components {
+		c.copyTo(&copies[i])
+		result[name] = &copies[i]
+		i++
+	}
+	return result
+}
+
+// reset clears the value/err pair of c and all state from loading it, this
+// must be called with the lock of the loader owning c.
+func (c *component) reset() {
+	c.instances = nil
+	c.failures = 0
+	c.failedDeps = nil
+	c.value = nil
+	c.err = nil
+	c.loaded = false
+	c.loadedAt = time.Time{}
+	c.duration = 0
+	c.version = ""
+	c.loading = false
+}
+
+// expire purges the value/err pair of c, if it is older than the TTL of c,
+// this must be called with the lock of the loader owning c.
+func (c *component) expire() {
+	expired := c.ttl > 0 && time.Since(c.loadedAt) >= c.ttl
+	if c.threshold > 0 && c.err != nil {
+		opened := c.failures - c.threshold + 1 // times the circuit has opened
+		expired = opened <= 0 || time.Since(c.loadedAt) >= c.cooldown.Delay(opened)
+	}
+	if expired && c.loaded && !c.overwritten {
+		c.value = nil
+		c.err = nil
+		c.loaded = false
+		c.loading = false
+	}
+}
+
+// expire purges the value/err pair of c as in component.expire(), or if the
+// error from loading c was caused by a dependency whose error has been purged,
+// this must be called with a.m locked.
+func (a *AcyclicLoader) expire(c *component) {
+	loaded := c.loaded
+	c.expire()
+	if !c.loaded {
+		if loaded {
+			a.generation++
+		}
+		return
+	}
+	for _, name := range c.failedDeps {
+		dep := a.components[name]
+		a.expire(dep)
+		if !dep.loaded && !dep.transient {
+			c.value = nil
+			c.err = nil
+			c.loaded = false
+			c.loading = false
+			a.generation++
+			return
+		}
+	}
+}
+
+// resolveDependencies populates the dependencies of the named component, and
+// checks that they are defined and have the expected types. The names of all
+// components are given for suggesting alternatives to undefined dependencies.
+func (a *AcyclicLoader) resolveDependencies(name string, names []string) error {
+	component := a.components[name]
+	t := component.fn.Type()
+	params := t.NumIn()
+	if component.keyed {
+		if params == 0 || t.In(0).Kind() != reflect.String {
+			return &ComponentDefinitionError{
 				Component: name,
 				message: fmt.Sprintf(
-					"expected no more than 1 input parameter for '%s', but found %d",
-					name, t.NumIn(),
+					"expected 1st input parameter for keyed component '%s' to be a string, but found %s",
+					name, t.String(),
 				),
 			}
 		}
-		input := t.In(0)
-		if input.Kind() != reflect.Struct {
-			return nil, &ComponentDefinitionError{
+		params--
+	}
+	var renamedFields []string // fields renamed by RenameDependency() or Mount()
+	switch params {
+	case 0:
+		// dependencies = nil
+	case 1:
+		input := optionsStruct(t.In(t.NumIn() - 1))
+		if input == nil {
+			return &ComponentDefinitionError{
 				Component: name,
 				message: fmt.Sprintf(
-					"expected input parameter for '%s' to be a struct, but found %s",
-					name, t.In(0).String(),
+					"expected input parameter for '%s' to be a struct or pointer to a struct, but found %s",
+					name, t.In(t.NumIn()-1).String(),
 				),
 			}
 		}
-		component.dependencies = make([]string, 0, input.NumField())
-		for i := 0; i < input.NumField(); i++ {
-			field := input.Field(i)
-			dep, ok := a.components[field.Name]
-			if !ok {
-				return nil, &ComponentDefinitionError{
+		var err error
+		walkDependencyFields(input, nil, func(field reflect.StructField, index []int) bool {
+			if field.PkgPath != "" {
+				err = &ComponentDefinitionError{
 					Component: name,
 					message: fmt.Sprintf(
-						"'%s' depends on undefined component '%s'",
+						"options struct for '%s' has unexported field '%s', which cannot be set, "+
+							"export the field or remove it",
 						name, field.Name,
 					),
 				}
+				return false
+			}
+			if field.Type == typeOfInfo {
+				component.infoFields = append(component.infoFields, index)
+				return true
+			}
+			if field.Type == typeOfContext {
+				component.contextFields = append(component.contextFields, index)
+				return true
+			}
+			depName, flags := parseComponentTag(field)
+			if stringContains(flags, "group") {
+				err = a.resolveGroup(name, field, index)
+				return err == nil
+			}
+			byType := stringContains(flags, "bytype")
+			if renamed, ok := component.renamed[field.Name]; ok {
+				depName = renamed
+				renamedFields = append(renamedFields, field.Name)
+				byType = false // renamed dependencies are wired by name
+			}
+			dep, ok := a.components[depName]
+			if byType {
+				ok = false
+			}
+			if !ok && (a.autoWire || byType) {
+				var wired string
+				wired, err = a.autoWireField(name, field, stringContains(flags, "lazy"))
+				if err != nil {
+					return false
+				}
+				if wired != "" {
+					depName = wired
+					dep, ok = a.components[depName]
+				}
 			}
-			if dep.result != field.Type {
-				return nil, &ComponentDefinitionError{
+			if !ok && byType && !stringContains(flags, "optional") {
+				err = &ComponentDefinitionError{
 					Component: name,
 					message: fmt.Sprintf(
-						"'%s' depends on component '%s' which has type %s, but '%s' expects %s",
-						name, field.Name, dep.result.String(), name, field.Type.String(),
+						"'%s' depends on a component of type %s, but no such component is defined",
+						name, field.Type.String(),
 					),
 				}
+				return false
 			}
-			component.dependencies = append(component.dependencies, field.Name)
+			def, hasDefault := field.Tag.Lookup("default")
+			if hasDefault && !stringContains(flags, "optional") {
+				err = &ComponentDefinitionError{
+					Component: name,
+					message: fmt.Sprintf(
+						"'%s' has a default value for '%s', but the dependency isn't optional",
+						name, field.Name,
+					),
+				}
+				return false
+			}
+			if !ok && stringContains(flags, "optional") {
+				if !hasDefault {
+					return true // optional dependencies are left as zero value
+				}
+				value, parseErr := parseDefault(field.Type, def)
+				if parseErr != nil {
+					err = &ComponentDefinitionError{
+						Component: name,
+						message: fmt.Sprintf(
+							"'%s' has an invalid default value for '%s': %s", name, field.Name, parseErr,
+						),
+					}
+					return false
+				}
+				component.defaults = append(component.defaults, fieldDefault{index: index, value: value})
+				return true
+			}
+			if !ok {
+				err = &ComponentDefinitionError{
+					Component: name,
+					message: fmt.Sprintf(
+						"'%s' depends on undefined component '%s'%s",
+						name, depName, describeAlternatives(suggestNames(depName, names), names),
+					),
+				}
+				return false
+			}
+			lazy := stringContains(flags, "lazy")
+			if dep.keyed {
+				// Keyed dependencies are always loaded by a getter taking the key
+				if !isKeyedLoadFuncFor(field.Type, dep.result) {
+					err = &ComponentDefinitionError{
+						Component: name,
+						message: fmt.Sprintf(
+							"'%s' depends on keyed component '%s' which has type %v, but '%s' expects %s",
+							name, depName, dep.result, name, field.Type.String(),
+						),
+					}
+					return false
+				}
+				lazy = true
+			} else if lazy && !isLoadFuncFor(field.Type, dep.result) {
+				err = &ComponentDefinitionError{
+					Component: name,
+					message: fmt.Sprintf(
+						"'%s' lazily depends on component '%s' which has type %v, but '%s' expects %s",
+						name, depName, dep.result, name, field.Type.String(),
+					),
+				}
+				return false
+			}
+			accessor := dep.element != nil && isPoolAccessorFor(field.Type, dep.element)
+			if !lazy && !accessor && (dep.result == nil || !dep.result.AssignableTo(field.Type)) {
+				err = &ComponentDefinitionError{
+					Component: name,
+					message: fmt.Sprintf(
+						"'%s' depends on component '%s' which has type %v, but '%s' expects %s",
+						name, depName, dep.result, name, field.Type.String(),
+					),
+				}
+				return false
+			}
+			component.dependencies = append(component.dependencies, depName)
+			component.fields = append(component.fields, index)
+			component.lazy = append(component.lazy, lazy)
+			component.grouped = append(component.grouped, false)
+			component.optional = append(component.optional, stringContains(flags, "optional"))
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		return &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"expected no more than 1 input parameter for '%s', but found %d",
+				name, params,
+			),
 		}
 	}
 
-	// Check for cycles
-	for _, name := range componentNames {
-		component := a.components[name]
-		cycle := a.detectCycles(component, []string{name})
-		if cycle != nil {
-			return nil, &ComponentDefinitionError{
-				Component: cycle[0],
+	for _, field := range sortedStringKeys(component.renamed) {
+		if !stringContains(renamedFields, field) {
+			return &ComponentDefinitionError{
+				Component: name,
 				message: fmt.Sprintf(
-					"dependency cycle detected: '%s'", strings.Join(cycle, "' -> '"),
+					"cannot rename dependency '%s' of '%s' to '%s', as it has no such field",
+					field, name, component.renamed[field],
 				),
 			}
 		}
 	}
 
-	return a, nil
+	if flag := component.enabledBy; flag != "" {
+		dep, ok := a.components[flag]
+		if !ok {
+			return &ComponentDefinitionError{
+				Component: name,
+				message: fmt.Sprintf(
+					"'%s' is enabled by undefined component '%s'%s",
+					name, flag, describeAlternatives(suggestNames(flag, names), names),
+				),
+			}
+		}
+		if dep.result != typeOfBool {
+			return &ComponentDefinitionError{
+				Component: name,
+				message: fmt.Sprintf(
+					"'%s' is enabled by component '%s' which has type %v, but expected bool",
+					name, flag, dep.result,
+				),
+			}
+		}
+		component.dependencies = append(component.dependencies, flag)
+		component.fields = append(component.fields, nil)
+		component.lazy = append(component.lazy, false)
+		component.grouped = append(component.grouped, false)
+		component.optional = append(component.optional, false)
+	}
+
+	for _, dep := range component.after {
+		if _, ok := a.components[dep]; !ok {
+			return &ComponentDefinitionError{
+				Component: name,
+				message: fmt.Sprintf(
+					"'%s' must load after undefined component '%s'%s",
+					name, dep, describeAlternatives(suggestNames(dep, names), names),
+				),
+			}
+		}
+		if stringContains(component.dependencies, dep) {
+			continue // already loaded before name
+		}
+		component.dependencies = append(component.dependencies, dep)
+		component.fields = append(component.fields, nil)
+		component.lazy = append(component.lazy, false)
+		component.grouped = append(component.grouped, false)
+		component.optional = append(component.optional, false)
+	}
+
+	if sem := component.semaphore; sem != "" && a.semaphores[sem] == nil {
+		return &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"'%s' is limited by undefined semaphore '%s', see WithSemaphore()", name, sem,
+			),
+		}
+	}
+
+	pooled := component.pool != 0 || component.element != nil
+	if pooled && !component.inherited && (component.pool < 1 || component.element == nil) {
+		return &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"cannot pool %d instances of '%s', a pool must have a positive size and a result",
+				component.pool, name,
+			),
+		}
+	}
+
+	for _, dep := range component.acknowledged {
+		if !stringContains(component.dependencies, dep) {
+			return &ComponentDefinitionError{
+				Component: name,
+				message: fmt.Sprintf(
+					"'%s' acknowledges concurrent use of '%s', but doesn't depend on it",
+					name, dep,
+				),
+			}
+		}
+	}
+	return nil
+}
+
+// resolveGroup adds all components assignable to the element type of field as
+// dependencies of the named component, in alphabetical order.
+func (a *AcyclicLoader) resolveGroup(name string, field reflect.StructField, index []int) error {
+	if field.Type.Kind() != reflect.Slice {
+		return &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"expected group field '%s' in '%s' to be a slice, but found %s",
+				field.Name, name, field.Type.String(),
+			),
+		}
+	}
+	component := a.components[name]
+	for _, member := range sortedKeys(a.components) {
+		c := a.components[member]
+		if member == name || c.keyed || c.result == nil || !c.result.AssignableTo(field.Type.Elem()) {
+			continue
+		}
+		component.dependencies = append(component.dependencies, member)
+		component.fields = append(component.fields, index)
+		component.lazy = append(component.lazy, false)
+		component.grouped = append(component.grouped, true)
+		component.optional = append(component.optional, true) // disabled members are skipped
+	}
+	return nil
+}
+
+// autoWireField returns the unique component assignable to the type of field,
+// or the empty string if there is no such component.
+func (a *AcyclicLoader) autoWireField(name string, field reflect.StructField, lazy bool) (string, error) {
+	t := field.Type
+	if lazy && t.Kind() == reflect.Func && t.NumOut() > 0 {
+		t = t.Out(0)
+	}
+	var matches []string
+	for _, candidate := range sortedKeys(a.components) {
+		c := a.components[candidate]
+		if candidate != name && !c.keyed && c.result != nil && c.result.AssignableTo(t) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) > 1 {
+		return "", &ComponentDefinitionError{
+			Component: name,
+			message: fmt.Sprintf(
+				"cannot auto-wire field '%s' in '%s', type %s is provided by components '%s'",
+				field.Name, name, t.String(), strings.Join(matches, "', '"),
+			),
+		}
+	}
+	if len(matches) == 0 {
+		return "", nil
+	}
+	return matches[0], nil
+}
+
+// warn returns err in strict mode, otherwise err is logged as a warning and
+// nil is returned.
+func (a *AcyclicLoader) warn(err error) error {
+	if err == nil || a.strict {
+		return err
+	}
+	a.logger.Printf("warning: %s", err)
+	return nil
 }
 
-func (a *AcyclicLoader) detectCycles(c *component, path []string) []string {
-	for _, dep := range c.dependencies {
-		for i, name := range path {
-			if name == dep {
-				return append(path[i:], dep)
+// detectCycles returns a cycle for each strongly connected component in the
+// dependency graph that contains a cycle. Each cycle starts and ends with the
+// alphabetically first component in the strongly connected component.
+func (a *AcyclicLoader) detectCycles() [][]string {
+	// Find strongly connected components using Tarjan's algorithm
+	index := make(map[string]int, len(a.components))
+	lowlink := make(map[string]int, len(a.components))
+	onStack := make(map[string]bool, len(a.components))
+	var stack []string
+	var sccs [][]string
+	var visit func(name string)
+	visit = func(name string) {
+		index[name] = len(index)
+		lowlink[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+		for _, dep := range a.components[name].dependencies {
+			if _, ok := index[dep]; !ok {
+				visit(dep)
+				if lowlink[dep] < lowlink[name] {
+					lowlink[name] = lowlink[dep]
+				}
+			} else if onStack[dep] && index[dep] < lowlink[name] {
+				lowlink[name] = index[dep]
 			}
 		}
-		if ret := a.detectCycles(a.components[dep], append(path, dep)); ret != nil {
-			return ret
+		if lowlink[name] == index[name] {
+			var scc []string
+			for {
+				n := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[n] = false
+				scc = append(scc, n)
+				if n == name {
+					break
+				}
+			}
+			sccs = append(sccs, scc)
+		}
+	}
+	for _, name := range sortedKeys(a.components) {
+		if _, ok := index[name]; !ok {
+			visit(name)
+		}
+	}
+
+	// Find the shortest cycle through the first component of each non-trivial
+	// strongly connected component
+	var cycles [][]string
+	for _, scc := range sccs {
+		sort.Strings(scc)
+		start := scc[0]
+		if len(scc) == 1 && !stringContains(a.components[start].dependencies, start) {
+			continue
+		}
+		cycles = append(cycles, a.shortestCycle(start, scc))
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// shortestCycle returns the shortest path from start back to start, only
+// visiting the given components.
+func (a *AcyclicLoader) shortestCycle(start string, components []string) []string {
+	previous := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dep := range a.components[name].dependencies {
+			if dep == start {
+				cycle := []string{start}
+				for n := name; n != start; n = previous[n] {
+					cycle = append(cycle, n)
+				}
+				cycle = append(cycle, start)
+				// Reverse the path, so it reads start -> ... -> start
+				for i, j := 1, len(cycle)-2; i < j; i, j = i+1, j-1 {
+					cycle[i], cycle[j] = cycle[j], cycle[i]
+				}
+				return cycle
+			}
+			if _, seen := previous[dep]; !seen && stringContains(components, dep) {
+				previous[dep] = name
+				queue = append(queue, dep)
+			}
 		}
 	}
 	return nil
 }
 
+// checkGoroutineSafety returns an error if component is depended on by
+// components that may be loaded concurrently, when it isn't goroutine-safe.
+func (a *AcyclicLoader) checkGoroutineSafety(component string) error {
+	c := a.components[component]
+	if !c.notGoroutineSafe || c.serialize != nil {
+		return nil
+	}
+
+	// Find dependents that haven't acknowledged concurrent use
+	var dependents []string
+	for _, name := range sortedKeys(a.components) {
+		dependent := a.components[name]
+		if stringContains(dependent.dependencies, component) &&
+			!stringContains(dependent.acknowledged, component) &&
+			!stringContains(dependent.after, component) {
+			dependents = append(dependents, name)
+		}
+	}
+
+	// Two dependents may be loaded concurrently, unless one depends on the other
+	reachable := make(map[string]map[string]bool, len(dependents))
+	for _, d := range dependents {
+		reachable[d] = a.transitiveDependencies(d)
+	}
+	for i, d1 := range dependents {
+		for _, d2 := range dependents[i+1:] {
+			if !reachable[d1][d2] && !reachable[d2][d1] {
+				return &ComponentDefinitionError{
+					Component: component,
+					message: fmt.Sprintf(
+						"'%s' is not goroutine-safe, but '%s' and '%s' depend on it "+
+							"and may be loaded concurrently",
+						component, d1, d2,
+					),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// transitiveDependencies returns the set of components that component depends
+// on transitively, visiting each component once.
+func (a *AcyclicLoader) transitiveDependencies(component string) map[string]bool {
+	reached := map[string]bool{}
+	var visit func(name string)
+	visit = func(name string) {
+		for _, dep := range a.components[name].dependencies {
+			if !reached[dep] {
+				reached[dep] = true
+				visit(dep)
+			}
+		}
+	}
+	visit(component)
+	return reached
+}
+
 // WithOverwrites returns an an AcyclicLoader with values overwriting the given
 // component names.
-func (a *AcyclicLoader) WithOverwrites(values map[string]interface{}) *AcyclicLoader {
-	a2 := &AcyclicLoader{
-		components: make(map[string]*component, len(a.components)),
+//
+// This returns an UndefinedOverwriteError if values contains names of
+// components that aren't defined, as this is most likely a typo.
+func (a *AcyclicLoader) WithOverwrites(values map[string]interface{}) (*AcyclicLoader, error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if err := a.checkFrozen("WithOverwrites"); err != nil {
+		return nil, err
+	}
+	if err := a.checkOverwrites(values); err != nil {
+		return nil, err
 	}
-	a2.c.L = &a2.m
+
+	a2 := a.derive()
 
 	// We need to purge any value/err pair that depends on something defined in
 	// values, as these are overwritten.
-	var needsPurging func(component string) bool
-	needsPurging = func(component string) bool {
-		if _, ok := values[component]; ok {
-			return true
+	needsPurging := a.needsPurging(values)
+
+	a2.components = copyComponents(a.components)
+	for name, c := range a2.components {
+		_, overwritten := values[name]
+		if needsPurging(name) && !overwritten && (c.loaded || len(c.instances) > 0) {
+			a2.purged = append(a2.purged, name)
 		}
-		for _, dep := range a.components[component].dependencies {
-			if needsPurging(dep) {
-				return true
-			}
+		if needsPurging(name) {
+			c.value = nil
+			c.err = nil
+			c.loaded = false
+			c.instances = nil
 		}
-		return false
+		if value, ok := values[name]; ok {
+			c.overwritten = true
+			c.value = value
+			c.err = nil
+			c.loaded = true
+		}
+		c.loading = c.loaded
 	}
+	sort.Strings(a2.purged)
+
+	return a2, nil
+}
 
+// WithOverwriteFuncs returns an AcyclicLoader with the functions loading the
+// given components replaced. This is useful for injecting a mock object that
+// still depends on other components.
+//
+// The functions given are validated the same way as in New(), and annotations
+// on the components replaced are preserved.
+func (a *AcyclicLoader) WithOverwriteFuncs(fns map[string]interface{}) (*AcyclicLoader, error) {
 	a.m.Lock()
-	for name, c := range a.components {
-		var value interface{}
-		var err error
-		var loaded bool
-		if !needsPurging(name) {
-			value = c.value
-			err = c.err
-			loaded = c.loaded
-		}
-		if val, ok := values[name]; ok {
-			value = val
-			err = nil
-		}
-		a2.components[name] = &component{
-			fn:           c.fn,
-			result:       c.result,
-			dependencies: c.dependencies,
-			value:        value,
-			err:          err,
-			loaded:       loaded,
-			loading:      loaded,
-		}
+	if err := a.checkFrozen("WithOverwriteFuncs"); err != nil {
+		a.m.Unlock()
+		return nil, err
+	}
+	if err := a.checkOverwrites(fns); err != nil {
+		a.m.Unlock()
+		return nil, err
 	}
+	definitions := make(Components, len(a.definitions))
+	for name, fn := range a.definitions {
+		definitions[name] = fn
+	}
+	options := a.options
 	a.m.Unlock()
 
+	for name, fn := range fns {
+		if an, ok := definitions[name].(*Annotated); ok {
+			if _, ok := fn.(*Annotated); !ok {
+				fn = Annotate(fn, an.annotations...)
+			}
+		}
+		definitions[name] = fn
+	}
+	a2, err := New(definitions, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep value/err pairs that don't depend on replaced functions, dependencies
+	// may have changed, so we use the new dependency graph.
+	needsPurging := a2.needsPurging(fns)
+	a2.purged = inheritCache(a, a2, func(name string) bool {
+		_, replaced := fns[name]
+		return !replaced && (a.components[name].overwritten || !needsPurging(name))
+	})
+	for name := range fns {
+		a2.components[name].forbidden = false // replaced functions are allowed
+	}
+
+	return a2, nil
+}
+
+// inheritCache copies value/err pairs from one AcyclicLoader to another, for
+// components where keep returns true. Components forbidden by Strict() remain
+// forbidden. This returns a sorted list of loaded components not kept.
+func inheritCache(from, to *AcyclicLoader, keep func(name string) bool) []string {
+	from.m.Lock()
+	defer from.m.Unlock()
+
+	var purged []string
+	for name, c := range to.components {
+		old, ok := from.components[name]
+		if ok {
+			c.forbidden = old.forbidden
+		}
+		if ok && !keep(name) && (old.loaded || len(old.instances) > 0) {
+			purged = append(purged, name)
+		}
+		if ok && keep(name) {
+			c.overwritten = old.overwritten
+			c.value = old.value
+			c.err = old.err
+			c.loaded = old.loaded
+			c.loadedAt = old.loadedAt
+			c.duration = old.duration
+			c.version = old.version
+			c.loading = old.loaded
+			if c.keyed && old.keyed {
+				c.instances = old.copy().instances
+			}
+		}
+	}
+	sort.Strings(purged)
+	return purged
+}
+
+// checkOverwrites returns an UndefinedOverwriteError if values contains names
+// of components that aren't defined, this must be called with a.m locked.
+func (a *AcyclicLoader) checkOverwrites(values map[string]interface{}) error {
+	var undefined []string
+	for name := range values {
+		if _, ok := a.components[name]; !ok {
+			undefined = append(undefined, name)
+		}
+	}
+	if len(undefined) == 0 {
+		return nil
+	}
+	sort.Strings(undefined)
+	names := sortedKeys(a.components)
+	suggestions := make(map[string][]string, len(undefined))
+	for _, name := range undefined {
+		suggestions[name] = suggestNames(name, names)
+	}
+	return &UndefinedOverwriteError{
+		Components:  undefined,
+		Suggestions: suggestions,
+		Available:   names,
+	}
+}
+
+// needsPurging returns a function that returns true, if the value/err pair of
+// a component depends on one of the components overwritten. Results are
+// memoized, so the returned function must not be used after modifying a.
+func (a *AcyclicLoader) needsPurging(overwritten map[string]interface{}) func(component string) bool {
+	memo := make(map[string]bool, len(a.components))
+	var needsPurging func(component string) bool
+	needsPurging = func(component string) bool {
+		if result, ok := memo[component]; ok {
+			return result
+		}
+		result := false
+		if _, ok := overwritten[component]; ok {
+			result = true
+		} else if !a.components[component].overwritten {
+			for _, dep := range a.components[component].dependencies {
+				if needsPurging(dep) {
+					result = true
+					break
+				}
+			}
+		}
+		memo[component] = result
+		return result
+	}
+	return needsPurging
+}
+
+// PurgedComponents returns a sorted list of components that were loaded in the
+// AcyclicLoader this was created from, but were purged as they depend on
+// components overwritten by WithOverwrites(), WithOverwriteFuncs() or similar.
+// These components will be loaded again when needed.
+func (a *AcyclicLoader) PurgedComponents() []string {
+	return append([]string(nil), a.purged...)
+}
+
+// derive returns an AcyclicLoader with the same definitions and options as a,
+// but without any components.
+func (a *AcyclicLoader) derive() *AcyclicLoader {
+	a2 := &AcyclicLoader{
+		definitions: a.definitions,
+		options:     a.options,
+		logger:      defaultLogger,
+	}
+	a2.c.L = &a2.m
+	for _, option := range a.options {
+		option(a2)
+	}
 	return a2
 }
 
@@ -279,27 +1194,127 @@ func (a *AcyclicLoader) WithOverwrites(values map[string]interface{}) *AcyclicLo
 // An AcyclicLoader caches loaded components internally, so when a global
 // instance in testing it is useful to create a clone of it.
 func (a *AcyclicLoader) Clone() *AcyclicLoader {
-	a2 := &AcyclicLoader{
-		components: make(map[string]*component, len(a.components)),
-	}
-	a2.c.L = &a2.m
-
 	a.m.Lock()
 	defer a.m.Unlock()
 
-	for name, c := range a.components {
-		a2.components[name] = &component{
-			fn:           c.fn,
-			result:       c.result,
-			dependencies: c.dependencies,
-			value:        c.value,
-			err:          c.err,
-			loaded:       c.loaded,
-			loading:      c.loaded,
+	a2 := a.derive()
+	a2.components = copyComponents(a.components)
+	return a2
+}
+
+// checkEnabled loads the feature flag enabling c, and returns a
+// DisabledComponentError if the flag is false, must be called with a.m locked.
+func (a *AcyclicLoader) checkEnabled(ctx context.Context, component string, c *component) error {
+	start := time.Now()
+	name := c.enabledBy
+	flag := a.components[name]
+	if !flag.loading {
+		a.goLoad(ctx, name)
+	}
+	for !flag.loaded {
+		a.c.Wait()
+	}
+	if flag.err != nil {
+		return dependencyError(component, name, time.Since(start), flag.duration, flag.err)
+	}
+	if enabled, _ := flag.value.(bool); !enabled {
+		return &DisabledComponentError{Component: component, Flag: name}
+	}
+	return nil
+}
+
+// dependencyError returns err from loading dep wrapped in a DependencyLoadError
+// for component, which failed after elapsed. If err is from dep itself, then
+// duration is the time spent in the function loading dep.
+func dependencyError(component, dep string, elapsed, duration time.Duration, err error) error {
+	switch e := err.(type) {
+	case *DependencyLoadError:
+		return e.extend(component, elapsed)
+	case *MultiDependencyLoadError:
+		return e.extend(component, elapsed)
+	}
+	return &DependencyLoadError{
+		trace:     []string{component, dep},
+		durations: []time.Duration{elapsed, duration},
+		err:       err,
+	}
+}
+
+// joinDependencyErrors returns errs from dependencyError() as a single error,
+// flattening errors from dependencies with several failed dependencies.
+func joinDependencyErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	var flattened []*DependencyLoadError
+	for _, err := range errs {
+		switch e := err.(type) {
+		case *DependencyLoadError:
+			flattened = append(flattened, e)
+		case *MultiDependencyLoadError:
+			flattened = append(flattened, e.Errors...)
 		}
 	}
+	return &MultiDependencyLoadError{Errors: flattened}
+}
 
-	return a2
+// serializeLocks returns the locks that must be held while loading c, because
+// c depends on components that require their dependents to be serialized. To
+// avoid deadlocks locks are always returned in sorted order.
+func (a *AcyclicLoader) serializeLocks(c *component) []*sync.Mutex {
+	var locks []*sync.Mutex
+	for _, dep := range sortedStrings(c.dependencies) {
+		if stringContains(c.after, dep) {
+			continue // ordering constraints don't use the value
+		}
+		if m := a.components[dep].serialize; m != nil {
+			locks = append(locks, m)
+		}
+	}
+	return locks
+}
+
+// call invokes the function loading c with the input given, while holding the
+// serialize locks given.
+func (a *AcyclicLoader) call(c *component, in []reflect.Value, serialize []*sync.Mutex) (value interface{}, err error) {
+	for _, m := range serialize {
+		m.Lock()
+		defer m.Unlock()
+	}
+
+	if c.pool > 0 {
+		return c.callPool(in)
+	}
+	return callRecover(c.fn, in, c.result != nil)
+}
+
+// callRecover calls fn with in and returns the value and error like
+// splitResults(), a panic in fn is returned as a PanicError.
+func callRecover(fn reflect.Value, in []reflect.Value, hasResult bool) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return splitResults(fn.Call(in), hasResult)
+}
+
+// splitResults returns the value and error from the results of calling a
+// function loading a component, hasResult is false if it only returns error.
+// A nil error is a nil interface{}, so the type assertion must not panic.
+func splitResults(ret []reflect.Value, hasResult bool) (value interface{}, err error) {
+	if hasResult {
+		value = ret[0].Interface()
+		if len(ret) > 1 {
+			err, _ = ret[1].Interface().(error)
+		}
+	} else if len(ret) == 1 {
+		err, _ = ret[0].Interface().(error)
+	}
+	return value, err
 }
 
 // MustLoad will load given component or panics
@@ -316,83 +1331,277 @@ func (a *AcyclicLoader) MustLoad(component string) interface{} {
 // An AcyclicLoader will always cache loaded components internally, to reload
 // components in testing using the Clone() method to create an AcyclicLoader
 // with a separate cache.
+//
+// The function loading a component may call Load() for other components, such
+// as plugins discovered at runtime. The loader isn't locked while it runs, and
+// locks required by SerializeDependents() already held by the goroutine are not
+// acquired again. If the other component depends on the component being loaded
+// Load() returns a ReentrantLoadError, rather than deadlocking.
 func (a *AcyclicLoader) Load(component string) (interface{}, error) {
+	return a.LoadContext(context.Background(), component)
+}
+
+// LoadContext loads and caches a given component like Load(), passing ctx to
+// components that have a context.Context field in their options struct.
+//
+// The context is passed to the functions loading the component and any
+// dependencies not already loaded. Note that if a function returns an error
+// because ctx is cancelled, the error is cached like any other error.
+func (a *AcyclicLoader) LoadContext(ctx context.Context, component string) (interface{}, error) {
 	a.m.Lock()
 	defer a.m.Unlock()
 
 	// Find the component
 	c, ok := a.components[component]
 	if !ok {
-		return nil, &UndefinedComponentError{Component: component}
+		names := sortedKeys(a.components)
+		return nil, &UndefinedComponentError{
+			Component:   component,
+			Suggestions: suggestNames(component, names),
+			Available:   names,
+		}
+	}
+	if c.keyed {
+		return nil, &KeyedComponentError{Component: component, Keyed: true}
+	}
+	if !c.loaded {
+		if err := a.checkReentrant(component); err != nil {
+			return nil, err
+		}
 	}
+	return a.load(ctx, component, c)
+}
 
-	// If loaded we're done
-	if c.loaded {
-		return c.value, c.err
+// load loads c, using component as the name of c in errors, middleware and
+// Info, this must be called with a.m locked.
+func (a *AcyclicLoader) load(ctx context.Context, component string, c *component) (interface{}, error) {
+	// If loaded we're done, unless the value has expired, and if another
+	// goroutine is loading c we wait for it, rather than loading c twice
+	for {
+		a.expireInherited()
+		a.expire(c)
+		if c.loaded || a.loadInherited(component, c) {
+			return c.value, c.err
+		}
+		if !c.loading || c.transient || a.holdsSerializeLock(c) {
+			break
+		}
+		a.c.Wait()
 	}
+	// Mark c as loading, transient components are never cached
+	c.loading = !c.transient
+	a.emit(LoadQueued, component, 0, nil)
+	c.queued++
+	defer func() { c.queued-- }()
 
-	// Create input argument
-	var in []reflect.Value
+	// Check the feature flag before loading any dependencies
+	began := time.Now()
 	var err error
-	if len(c.dependencies) > 0 {
-		input := reflect.New(c.fn.Type().In(0)).Elem()
-		in = []reflect.Value{input}
+	if c.enabledBy != "" {
+		err = a.checkEnabled(ctx, component, c)
+	}
 
-		// Ensure that we're recursively loading all dependencies
-		for _, dep := range c.dependencies {
-			if !a.components[dep].loading {
-				go a.Load(dep)
+	// Components forbidden by Strict() fail without loading dependencies
+	if err == nil && c.forbidden && !c.overwritten {
+		err = &ForbiddenComponentError{Component: component}
+	}
+
+	// Create input argument
+	var in []reflect.Value
+	var failedDeps []string
+	if err == nil && (c.fn.Type().NumIn() == 1 || len(c.dependencies) > 0) {
+		// Functions without an options struct may have ordering constraints
+		var input reflect.Value
+		if c.fn.Type().NumIn() == 1 {
+			var arg reflect.Value
+			arg, input = newOptions(c.fn.Type().In(0))
+			in = []reflect.Value{arg}
+			for _, d := range c.defaults {
+				input.FieldByIndex(d.index).Set(d.value)
 			}
 		}
 
-		// Wait for dependencies to be loaded
+		// Ensure that we're recursively loading all dependencies, without
+		// spawning goroutines for dependencies that are already cached
 		for i, dep := range c.dependencies {
-			for !a.components[dep].loaded {
-				a.c.Wait()
+			if c.lazy[i] {
+				continue
 			}
-			// If there is an error we wrap and break
-			err = a.components[dep].err
-			if err != nil {
-				if e, ok := err.(*DependencyLoadError); ok {
-					err = e.extend(component)
+			d := a.components[dep]
+			a.expire(d)
+			if d.loaded || d.loading || d.transient || a.loadInherited(dep, d) {
+				continue
+			}
+			a.goLoad(ctx, dep)
+		}
+
+		// Wait for dependencies to be loaded, collecting all that failed
+		var errs []error
+		for i, dep := range c.dependencies {
+			if c.lazy[i] {
+				// Lazy dependencies are loaded when the getter is called
+				field := input.FieldByIndex(c.fields[i])
+				if a.components[dep].keyed {
+					field.Set(a.keyedLoadFunc(dep, field.Type()))
 				} else {
-					err = &DependencyLoadError{
-						trace: []string{component, dep},
-						err:   err,
-					}
+					field.Set(a.loadFunc(dep, field.Type().Out(0)))
+				}
+				continue
+			}
+			var value interface{}
+			var duration time.Duration
+			if a.components[dep].transient {
+				// Transient dependencies are loaded for each dependent
+				a.m.Unlock()
+				start := time.Now()
+				value, err = a.LoadContext(ctx, dep)
+				duration = time.Since(start)
+				a.m.Lock()
+			} else {
+				for !a.components[dep].loaded {
+					a.c.Wait()
 				}
-				break
+				value, err = a.components[dep].value, a.components[dep].err
+				duration = a.components[dep].duration
+			}
+			// If there is an error we wrap it and keep waiting for the rest
+			if _, disabled := err.(*DisabledComponentError); disabled && c.optional[i] {
+				continue // optional dependencies are left as zero value
+			}
+			if err != nil {
+				errs = append(errs, dependencyError(component, dep, time.Since(began), duration, err))
+				failedDeps = append(failedDeps, dep)
+				continue
+			}
+			if len(errs) > 0 || c.fields[i] == nil {
+				continue // feature flag checked by checkEnabled, or ordering constraint
+			}
+			field := input.FieldByIndex(c.fields[i])
+			if d := a.components[dep]; d.element != nil && field.Kind() == reflect.Func {
+				field.Set(poolAccessor(field.Type(), value))
+				continue
+			}
+			if c.grouped[i] {
+				field.Set(reflect.Append(field, valueOf(field.Type().Elem(), value)))
+				continue
 			}
-			input.Field(i).Set(reflect.ValueOf(a.components[dep].value))
+			field.Set(valueOf(field.Type(), value))
+		}
+		err = joinDependencyErrors(errs)
+		for _, index := range c.infoFields {
+			input.FieldByIndex(index).Set(reflect.ValueOf(Info{
+				Name:         component,
+				Dependencies: append([]string(nil), c.dependencies...),
+				Metadata:     a.metadata,
+			}))
+		}
+		for _, index := range c.contextFields {
+			labeled := pprof.WithLabels(ctx, pprof.Labels("component", component))
+			input.FieldByIndex(index).Set(reflect.ValueOf(&labeled).Elem())
 		}
 	}
 
-	// Mark c as loading
-	c.loading = true
-
 	// Obtain value, if no error so far
 	var value interface{}
+	var duration time.Duration
+	var version string
 	if err == nil {
+		// Locks already held by this goroutine, because it is loading c from
+		// within the function loading another component, are not acquired again
+		serialize, leave := a.enterConstructor(component, a.serializeLocks(c))
+		a.active.add(1)
+		a.emit(LoadStarted, component, 0, nil)
 		a.m.Unlock()
 
-		// Call the loader to obtain value and err
-		ret := c.fn.Call(in)
-		if c.result != nil {
-			value = ret[0].Interface()
-			if len(ret) > 1 {
-				err = ret[1].Interface().(error)
+		// Wait for the semaphore limiting c, if any, and then for the scheduler
+		if sem := a.semaphores[c.semaphore]; sem != nil {
+			sem <- struct{}{}
+		}
+		if a.scheduler != nil {
+			a.scheduler.acquire(c.priority)
+		}
+
+		// Obtain the version before loading, such that changes while loading
+		// are detected by RefreshChanged()
+		if c.versionFunc != nil {
+			version = c.versionFunc()
+		}
+
+		// Call the loader through middleware to obtain value and err
+		load := func() (value interface{}, err error) {
+			// Label the goroutine, such that profiles attribute cost to c
+			pprof.Do(ctx, pprof.Labels("component", component), func(context.Context) {
+				value, err = a.call(c, in, serialize)
+			})
+			return value, err
+		}
+		for _, middleware := range a.middleware {
+			load = middleware(component, load)
+		}
+		start := time.Now()
+		stop := a.watchSlow(component, start)
+		value, err = load()
+		duration = time.Since(start)
+		stop()
+		if a.scheduler != nil {
+			a.scheduler.release()
+		}
+		if sem := a.semaphores[c.semaphore]; sem != nil {
+			<-sem
+		}
+
+		if err == nil && len(a.middleware) > 0 && c.result != nil && value != nil &&
+			!reflect.TypeOf(value).AssignableTo(c.result) {
+			err = &MiddlewareResultError{
+				Component: component,
+				Type:      reflect.TypeOf(value),
+				Expected:  c.result,
 			}
-		} else if len(ret) == 1 {
-			err = ret[0].Interface().(error)
+		}
+		if err == nil && a.rejectNil && !c.allowNil && isNilResult(c.result, value) {
+			err = &NilResultError{Component: component, Type: c.result}
 		}
 
 		a.m.Lock()
+		leave()
+		a.active.add(-1)
+		if len(a.recorders) > 0 {
+			var dependencies []string
+			for i, dep := range c.dependencies {
+				if !c.lazy[i] {
+					dependencies = append(dependencies, dep)
+				}
+			}
+			for _, r := range a.recorders {
+				r.record(component, dependencies, start, duration, err)
+			}
+		}
+	}
+	if err != nil {
+		a.emit(LoadFailed, component, duration, err)
+	} else {
+		a.emit(LoadFinished, component, duration, nil)
+	}
+	if c.transient {
+		return value, err
 	}
 
 	// Set value and inform anyone blocked
+	if err != nil {
+		c.failures++
+	} else {
+		c.failures = 0
+	}
+	c.failedDeps = failedDeps
 	c.loaded = true
+	c.loadedAt = time.Now()
+	c.duration = duration
+	c.version = version
 	c.value = value
 	c.err = err
+	if c.inherited {
+		a.inheritLoadedAt(component, c)
+	}
 	a.c.Broadcast()
 
 	return c.value, c.err
@@ -1,6 +1,15 @@
 package acyclicloader
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestAcyclicLoader(t *testing.T) {
 	i := 5
@@ -35,12 +44,28 @@ func TestAcyclicLoader(t *testing.T) {
 	}
 
 	// We can also overwrite
-	v, _ = loader.WithOverwrites(map[string]interface{}{"StaticInt": 7}).Load("Plus7")
+	overwritten, _ := loader.WithOverwrites(map[string]interface{}{"StaticInt": 7})
+	v, _ = overwritten.Load("Plus7")
 	if v.(int) != 14 {
 		t.Error("Expected 14")
 	}
 }
 
+func TestNilErrorResults(t *testing.T) {
+	loader := Components{
+		"Value": func() (int, error) { return 42, nil },
+		"Check": func() error { return nil },
+	}.AsLoader()
+
+	v, err := loader.Load("Value")
+	if err != nil || v.(int) != 42 {
+		t.Errorf("expected 42 without error, got %v, %v", v, err)
+	}
+	if _, err := loader.Load("Check"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
 func TestCyclicDependencies(t *testing.T) {
 	_, err := New(Components{
 		"A": func(options struct{ B int }) int { return options.B + 5 },
@@ -95,3 +120,809 @@ func TestDependencyTypeMismatch(t *testing.T) {
 		t.Error("expected an error")
 	}
 }
+
+func TestMultipleCyclicDependencies(t *testing.T) {
+	_, err := New(Components{
+		"A": func(options struct{ B int }) int { return options.B + 5 },
+		"B": func(options struct{ A int }) int { return options.A + 5 },
+		"C": func(options struct{ D int }) int { return options.D + 5 },
+		"D": func(options struct{ E int }) int { return options.E + 5 },
+		"E": func(options struct{ C int }) int { return options.C + 5 },
+		"F": func(options struct{ F int }) int { return options.F + 5 },
+		"G": func(options struct{ A int }) int { return options.A + 5 },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "3 dependency cycles detected: 'A' -> 'B' -> 'A', " +
+		"'C' -> 'D' -> 'E' -> 'C', 'F' -> 'F'"
+	if err.Error() != expected {
+		t.Errorf("expected error: '%s'", expected)
+	}
+}
+
+func TestUndefinedComponentSuggestions(t *testing.T) {
+	loader, _ := New(Components{
+		"Database": func() int { return 5 },
+		"Template": func() int { return 5 },
+	})
+	_, err := loader.Load("Databse")
+	t.Logf("got error as expected: '%s'", err)
+	e, ok := err.(*UndefinedComponentError)
+	if !ok {
+		t.Fatal("expected an UndefinedComponentError")
+	}
+	if len(e.Suggestions) != 1 || e.Suggestions[0] != "Database" {
+		t.Error("expected 'Database' to be suggested")
+	}
+	if len(e.Available) != 2 {
+		t.Error("expected two available components")
+	}
+
+	_, err = New(Components{
+		"Database": func() int { return 5 },
+		"A":        func(options struct{ Databse int }) int { return options.Databse },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "did you mean 'Database'?") {
+		t.Error("expected an error suggesting 'Database'")
+	}
+}
+
+func TestDependencyLoadErrorUnwrap(t *testing.T) {
+	rootCause := errors.New("connection refused")
+	loader, _ := New(Components{
+		"Database": func() (int, error) { return 0, rootCause },
+		"Users":    func(options struct{ Database int }) int { return options.Database },
+		"Server":   func(options struct{ Users int }) int { return options.Users },
+	})
+	_, err := loader.Load("Server")
+	t.Logf("got error as expected: '%s'", err)
+	if !errors.Is(err, rootCause) {
+		t.Error("expected errors.Is to find the root cause")
+	}
+	var e *DependencyLoadError
+	if !errors.As(err, &e) {
+		t.Fatal("expected a DependencyLoadError")
+	}
+	if strings.Join(e.Trace(), " -> ") != "Server -> Users -> Database" {
+		t.Error("unexpected trace: ", e.Trace())
+	}
+}
+
+func TestUndefinedOverwrites(t *testing.T) {
+	loader, _ := New(Components{
+		"Database": func() int { return 5 },
+		"Port":     func() int { return 80 },
+	})
+	_, err := loader.WithOverwrites(map[string]interface{}{
+		"Databse": 7,
+		"Port":    8080,
+		"Xyz":     1,
+	})
+	t.Logf("got error as expected: '%s'", err)
+	e, ok := err.(*UndefinedOverwriteError)
+	if !ok {
+		t.Fatal("expected an UndefinedOverwriteError")
+	}
+	if strings.Join(e.Components, ", ") != "Databse, Xyz" {
+		t.Error("unexpected undefined components: ", e.Components)
+	}
+}
+
+func TestOverwriteValues(t *testing.T) {
+	loader, _ := New(Components{
+		"StaticInt": func() int { return 5 },
+		"Plus7": func(options struct {
+			StaticInt int
+		}) int {
+			return options.StaticInt + 7
+		},
+	})
+	overwritten, _ := loader.WithOverwrites(map[string]interface{}{"StaticInt": 10})
+	if overwritten.MustLoad("Plus7").(int) != 17 {
+		t.Error("Expected 17")
+	}
+	if loader.MustLoad("Plus7").(int) != 12 {
+		t.Error("Expected 12")
+	}
+}
+
+func TestCloneSharesDefinitions(t *testing.T) {
+	loader, _ := New(Components{
+		"StaticInt": func() int { return 5 },
+		"Plus7": func(options struct {
+			StaticInt int
+		}) int {
+			return options.StaticInt + 7
+		},
+	})
+	loader.MustLoad("Plus7")
+	overwritten, _ := loader.WithOverwrites(map[string]interface{}{"StaticInt": 10})
+	clone := overwritten.Clone()
+	for name, c := range loader.components {
+		if clone.components[name].definition != c.definition {
+			t.Errorf("expected clone to share definition of '%s'", name)
+		}
+	}
+	if !clone.components["StaticInt"].overwritten || clone.components["Plus7"].loaded {
+		t.Error("expected clone to copy state of the loader cloned")
+	}
+	if !loader.components["Plus7"].loaded {
+		t.Error("expected original loader to be unaffected")
+	}
+}
+
+func TestPurgedComponents(t *testing.T) {
+	loader, _ := New(Components{
+		"StaticInt": func() int { return 5 },
+		"Other":     func() int { return 3 },
+		"Plus7": func(options struct {
+			StaticInt int
+		}) int {
+			return options.StaticInt + 7
+		},
+		"Unloaded": func(options struct {
+			StaticInt int
+		}) int {
+			return options.StaticInt
+		},
+	})
+	loader.MustLoad("Plus7")
+	loader.MustLoad("Other")
+
+	overwritten, _ := loader.WithOverwrites(map[string]interface{}{"StaticInt": 10})
+	if purged := overwritten.PurgedComponents(); len(purged) != 1 || purged[0] != "Plus7" {
+		t.Error("expected 'Plus7' to be purged, got: ", purged)
+	}
+	replaced, _ := loader.WithOverwriteFuncs(map[string]interface{}{
+		"StaticInt": func() int { return 10 },
+	})
+	if purged := replaced.PurgedComponents(); len(purged) != 2 || purged[0] != "Plus7" || purged[1] != "StaticInt" {
+		t.Error("expected 'Plus7' and 'StaticInt' to be purged, got: ", purged)
+	}
+	if purged := loader.Clone().PurgedComponents(); len(purged) != 0 {
+		t.Error("expected nothing to be purged by Clone(), got: ", purged)
+	}
+}
+
+func TestOverwriteDeepDiamond(t *testing.T) {
+	// Each level depends twice on the level below, such that checking which
+	// components need purging without memoization takes exponential time.
+	components := Components{"L0": func() int { return 0 }, "R0": func() int { return 0 }}
+	for i := 1; i <= 64; i++ {
+		fn := reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{reflect.StructOf([]reflect.StructField{
+				{Name: "Left", Type: reflect.TypeOf(0), Tag: reflect.StructTag(fmt.Sprintf(`component:"L%d"`, i-1))},
+				{Name: "Right", Type: reflect.TypeOf(0), Tag: reflect.StructTag(fmt.Sprintf(`component:"R%d"`, i-1))},
+			})}, []reflect.Type{reflect.TypeOf(0)}, false),
+			func(args []reflect.Value) []reflect.Value {
+				return []reflect.Value{reflect.ValueOf(int(args[0].Field(0).Int()) + 1)}
+			},
+		).Interface()
+		components[fmt.Sprintf("L%d", i)] = fn
+		components[fmt.Sprintf("R%d", i)] = fn
+	}
+	loader, err := New(components)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	loader.MustLoad("L64")
+	overwritten, _ := loader.WithOverwrites(map[string]interface{}{"L0": 1})
+	if v := overwritten.MustLoad("L64"); v != 65 {
+		t.Error("expected 65, got: ", v)
+	}
+	if purged := overwritten.PurgedComponents(); len(purged) != 127 {
+		t.Error("expected all loaded components but 'L0' and 'R0' to be purged, got: ", len(purged))
+	}
+}
+
+func TestOverwriteFuncs(t *testing.T) {
+	loader, _ := New(Components{
+		"StaticInt": func() int { return 5 },
+		"Other":     func() int { return 3 },
+		"Plus7": func(options struct {
+			StaticInt int
+		}) int {
+			return options.StaticInt + 7
+		},
+	})
+	if loader.MustLoad("Plus7").(int) != 12 {
+		t.Error("Expected 12")
+	}
+
+	// Replace "Plus7" with a function that depends on different components
+	replaced, err := loader.WithOverwriteFuncs(map[string]interface{}{
+		"Plus7": func(options struct{ StaticInt, Other int }) int {
+			return options.StaticInt + options.Other
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if replaced.MustLoad("Plus7").(int) != 8 {
+		t.Error("Expected 8")
+	}
+
+	// Replacement functions are validated
+	_, err = loader.WithOverwriteFuncs(map[string]interface{}{
+		"StaticInt": func() string { return "5" },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestConstantComponents(t *testing.T) {
+	type BuildInfo struct{ Version string }
+	loader, err := New(Components{
+		"Port":      80,
+		"BuildInfo": &BuildInfo{Version: "1.2.3"},
+		"Tracing":   Annotate(true, Tagged("debug")),
+		"Address": func(options struct {
+			Port      int
+			BuildInfo *BuildInfo
+		}) string {
+			return fmt.Sprintf("%s:%d", options.BuildInfo.Version, options.Port)
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Address").(string) != "1.2.3:80" {
+		t.Error("expected '1.2.3:80'")
+	}
+	if !loader.MustLoad("Tracing").(bool) {
+		t.Error("expected true")
+	}
+
+	decorated, err := loader.Decorate("Port", func(port int) int { return port + 1 })
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if decorated.MustLoad("Port").(int) != 81 {
+		t.Error("expected 81")
+	}
+
+	_, err = New(Components{"Port": nil})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for nil definition")
+	}
+}
+
+func TestComponentTag(t *testing.T) {
+	loader, err := New(Components{
+		"read-replica": func() string { return "replica" },
+		"Users": func(options struct {
+			Database string `component:"read-replica"`
+		}) string {
+			return options.Database
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Users").(string) != "replica" {
+		t.Error("expected 'replica'")
+	}
+
+	_, err = New(Components{
+		"Database": func() string { return "db" },
+		"Users": func(options struct {
+			Database string `component:"ReadReplica"`
+		}) string {
+			return options.Database
+		},
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestDefaultTag(t *testing.T) {
+	type settings struct {
+		Port    int           `component:",optional" default:"8080"`
+		Host    string        `component:",optional" default:"localhost"`
+		Timeout time.Duration `component:",optional" default:"30s"`
+		Origins []string      `component:",optional" default:"[\"a\", \"b\"]"`
+	}
+	server := func(options settings) settings { return options }
+
+	loader := Components{"Server": server}.AsLoader()
+	s := loader.MustLoad("Server").(settings)
+	if s.Port != 8080 || s.Host != "localhost" || s.Timeout != 30*time.Second || len(s.Origins) != 2 {
+		t.Error("expected defaults, got: ", s)
+	}
+
+	loader = Components{
+		"Server": server,
+		"Port":   func() int { return 80 },
+	}.AsLoader()
+	if s := loader.MustLoad("Server").(settings); s.Port != 80 || s.Host != "localhost" {
+		t.Error("expected defined components to take precedence, got: ", s)
+	}
+
+	if _, err := New(Components{
+		"Server": func(options struct {
+			Port int `component:",optional" default:"http"`
+		}) int {
+			return options.Port
+		},
+	}); err == nil {
+		t.Error("expected an error for an invalid default")
+	}
+	if _, err := New(Components{
+		"Port": func() int { return 80 },
+		"Server": func(options struct {
+			Port int `default:"8080"`
+		}) int {
+			return options.Port
+		},
+	}); err == nil {
+		t.Error("expected an error for a default on a required dependency")
+	}
+}
+
+func TestUnexportedOptionsField(t *testing.T) {
+	type shared struct {
+		Name string
+	}
+	_, err := New(Components{
+		"Name": func() string { return "service" },
+		"Service": func(options struct {
+			Name     string
+			database string
+		}) string {
+			return options.Name
+		},
+	})
+	e, ok := err.(*ComponentDefinitionError)
+	if !ok || e.Component != "Service" || !strings.Contains(err.Error(), "unexported field 'database'") {
+		t.Error("expected a ComponentDefinitionError for the unexported field, got: ", err)
+	}
+
+	// Exported fields of embedded structs with unexported types can be set
+	loader := Components{
+		"Name":    func() string { return "service" },
+		"Service": func(options struct{ shared }) string { return options.Name },
+	}.AsLoader()
+	if loader.MustLoad("Service") != "service" {
+		t.Error("expected 'service'")
+	}
+}
+
+func TestOptionalDependency(t *testing.T) {
+	service := func(options struct {
+		Name   string
+		Tracer *strings.Builder `component:",optional"`
+	}) string {
+		if options.Tracer != nil {
+			options.Tracer.WriteString("traced")
+		}
+		return options.Name
+	}
+
+	loader, err := New(Components{
+		"Name":    func() string { return "service" },
+		"Service": service,
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Service").(string) != "service" {
+		t.Error("expected 'service'")
+	}
+
+	tracer := &strings.Builder{}
+	loader, _ = New(Components{
+		"Name":    func() string { return "service" },
+		"Tracer":  func() *strings.Builder { return tracer },
+		"Service": service,
+	})
+	loader.MustLoad("Service")
+	if tracer.String() != "traced" {
+		t.Error("expected optional dependency to be injected")
+	}
+	if len(Components{
+		"Tracer":  func() *strings.Builder { return tracer },
+		"Name":    func() string { return "service" },
+		"Service": service,
+	}.Without("Tracer")) != 2 {
+		t.Error("expected Without to keep components with optional dependencies")
+	}
+}
+
+type commonDeps struct {
+	Prefix string
+	Suffix string
+}
+
+func TestEmbeddedOptions(t *testing.T) {
+	loader, err := New(Components{
+		"Prefix": func() string { return "<" },
+		"Suffix": func() string { return ">" },
+		"Name":   func() string { return "name" },
+		"Tag": func(options struct {
+			commonDeps
+			Name string
+		}) string {
+			return options.Prefix + options.Name + options.Suffix
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Tag").(string) != "<name>" {
+		t.Error("expected '<name>'")
+	}
+
+	// Decorators also support embedded structs
+	decorated, err := loader.Decorate("Tag", func(tag string, options struct {
+		commonDeps
+	}) string {
+		return options.Prefix + tag + options.Suffix
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if decorated.MustLoad("Tag").(string) != "<<name>>" {
+		t.Error("expected '<<name>>'")
+	}
+}
+
+func TestPointerOptions(t *testing.T) {
+	loader, err := New(Components{
+		"A": func() int { return 5 },
+		"B": func(options *struct{ A int }) int { return options.A + 1 },
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("B").(int) != 6 {
+		t.Error("expected 6")
+	}
+
+	decorated, err := loader.Decorate("B", func(b int, options *struct{ A int }) int {
+		return b * options.A
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if decorated.MustLoad("B").(int) != 30 {
+		t.Error("expected 30")
+	}
+
+	_, err = New(Components{
+		"A": func(options *int) int { return *options },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestLazyDependency(t *testing.T) {
+	loaded := false
+	loader, err := New(Components{
+		"Heavy": func() string {
+			loaded = true
+			return "heavy"
+		},
+		"Service": func(options struct {
+			Heavy func() (string, error) `component:",lazy"`
+		}) func() (string, error) {
+			return options.Heavy
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	getter := loader.MustLoad("Service").(func() (string, error))
+	if loaded {
+		t.Error("expected 'Heavy' not to be loaded yet")
+	}
+	if v, err := getter(); v != "heavy" || err != nil {
+		t.Error("expected 'heavy'")
+	}
+	if !loaded {
+		t.Error("expected 'Heavy' to be loaded")
+	}
+
+	_, err = New(Components{
+		"Heavy": func() string { return "heavy" },
+		"Service": func(options struct {
+			Heavy func() string `component:",lazy"`
+		}) int {
+			return 0
+		},
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestInfo(t *testing.T) {
+	loader, err := New(Components{
+		"A": func() int { return 5 },
+		"B": func(options struct {
+			A    int
+			Info Info
+		}) string {
+			return options.Info.Name + ":" + strings.Join(options.Info.Dependencies, ",") +
+				":" + options.Info.Metadata["env"]
+		},
+	}, WithMetadata(map[string]string{"env": "test"}))
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("B").(string) != "B:A:test" {
+		t.Error("expected 'B:A:test', got: ", loader.MustLoad("B"))
+	}
+}
+
+func TestContextField(t *testing.T) {
+	type key struct{}
+	loader, _ := New(Components{
+		"A": func(options struct{ Context context.Context }) string {
+			return options.Context.Value(key{}).(string)
+		},
+		"B": func(options struct {
+			A   string
+			Ctx context.Context
+		}) string {
+			return options.A + options.Ctx.Value(key{}).(string)
+		},
+	})
+	ctx := context.WithValue(context.Background(), key{}, "ctx")
+	v, err := loader.LoadContext(ctx, "B")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if v.(string) != "ctxctx" {
+		t.Error("expected 'ctxctx'")
+	}
+}
+
+func TestProfilerLabels(t *testing.T) {
+	loader, _ := New(Components{
+		"A": func(options struct{ Context context.Context }) string {
+			label, _ := pprof.Label(options.Context, "component")
+			return label
+		},
+	})
+	if v := loader.MustLoad("A"); v != "A" {
+		t.Error("expected context to be labeled with component name, got: ", v)
+	}
+}
+
+type testGreeter interface {
+	Greet() string
+}
+
+type englishGreeter struct{}
+
+func (*englishGreeter) Greet() string { return "hello" }
+
+func TestAssignableDependency(t *testing.T) {
+	loader, err := New(Components{
+		"Greeter": func() *englishGreeter { return &englishGreeter{} },
+		"Message": func(options struct {
+			Greeter testGreeter
+			Lazy    func() (testGreeter, error) `component:"Greeter,lazy"`
+		}) string {
+			lazy, _ := options.Lazy()
+			return options.Greeter.Greet() + " " + lazy.Greet()
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Message").(string) != "hello hello" {
+		t.Error("expected 'hello hello', got: ", loader.MustLoad("Message"))
+	}
+}
+
+type germanGreeter struct{}
+
+func (germanGreeter) Greet() string { return "hallo" }
+
+func TestGroupDependency(t *testing.T) {
+	components := Components{
+		"English": func() *englishGreeter { return &englishGreeter{} },
+		"German":  func() germanGreeter { return germanGreeter{} },
+		"Port":    func() int { return 80 },
+		"Message": func(options struct {
+			Greeters []testGreeter `component:",group"`
+		}) string {
+			var greetings []string
+			for _, greeter := range options.Greeters {
+				greetings = append(greetings, greeter.Greet())
+			}
+			return strings.Join(greetings, ", ")
+		},
+	}
+	loader, err := New(components)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Message").(string) != "hello, hallo" {
+		t.Error("expected 'hello, hallo', got: ", loader.MustLoad("Message"))
+	}
+	if _, ok := components.Without("German")["Message"]; !ok {
+		t.Error("expected 'Message' to remain when removing a group member")
+	}
+
+	_, err = New(Components{
+		"Message": func(options struct {
+			Greeters testGreeter `component:",group"`
+		}) string {
+			return ""
+		},
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestAutoWiring(t *testing.T) {
+	components := Components{
+		"Database": func() *strings.Builder { return &strings.Builder{} },
+		"English":  func() *englishGreeter { return &englishGreeter{} },
+		"Message": func(options struct {
+			Builder *strings.Builder
+			Greeter testGreeter
+			Lazy    func() (testGreeter, error) `component:",lazy"`
+		}) string {
+			lazy, _ := options.Lazy()
+			options.Builder.WriteString(lazy.Greet())
+			return options.Greeter.Greet()
+		},
+	}
+	_, err := New(components)
+	if err == nil {
+		t.Error("expected an error without auto-wiring")
+	}
+
+	loader, err := New(components, WithAutoWiring())
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Message").(string) != "hello" {
+		t.Error("expected 'hello'")
+	}
+	if loader.MustLoad("Database").(*strings.Builder).String() != "hello" {
+		t.Error("expected 'Builder' to be wired to 'Database'")
+	}
+
+	components["German"] = func() germanGreeter { return germanGreeter{} }
+	_, err = New(components, WithAutoWiring())
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "'English', 'German'") {
+		t.Error("expected an error listing the ambiguous components")
+	}
+}
+
+func TestMultiDependencyLoadError(t *testing.T) {
+	databaseErr := errors.New("connection refused")
+	cacheErr := errors.New("no such host")
+	loader, _ := New(Components{
+		"Database": func() (int, error) { return 0, databaseErr },
+		"Cache":    func() (int, error) { return 0, cacheErr },
+		"Port":     func() int { return 80 },
+		"Users": func(options struct {
+			Database int
+			Cache    int
+		}) int {
+			return 0
+		},
+		"Server": func(options struct {
+			Port  int
+			Users int
+			Cache int
+		}) int {
+			return 0
+		},
+	})
+	_, err := loader.Load("Server")
+	t.Logf("got error as expected: '%s'", err)
+	e, ok := err.(*MultiDependencyLoadError)
+	if !ok {
+		t.Fatal("expected a MultiDependencyLoadError")
+	}
+	var traces []string
+	for _, err := range e.Errors {
+		traces = append(traces, strings.Join(err.Trace(), " -> "))
+	}
+	if strings.Join(traces, ", ") != "Server -> Users -> Database, Server -> Users -> Cache, Server -> Cache" {
+		t.Error("unexpected traces: ", traces)
+	}
+	if !errors.Is(e.Errors[0], databaseErr) || !errors.Is(e.Errors[2], cacheErr) {
+		t.Error("expected each error to unwrap to its root cause")
+	}
+	if !errors.Is(err, databaseErr) || !errors.Is(err, cacheErr) {
+		t.Error("expected errors.Is() to find the root cause of each failed dependency")
+	}
+	if !e.Is(databaseErr) || !e.Is(cacheErr) || e.Is(context.Canceled) {
+		t.Error("expected Is() to match root causes without multi-error unwrapping")
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Error("expected errors.Is() not to find unrelated errors")
+	}
+	var loadErr *DependencyLoadError
+	if !errors.As(err, &loadErr) || loadErr != e.Errors[0] {
+		t.Error("expected errors.As() to find the first DependencyLoadError")
+	}
+
+	_, err = loader.Load("Users")
+	if _, ok := err.(*MultiDependencyLoadError); !ok {
+		t.Error("expected a MultiDependencyLoadError for 'Users'")
+	}
+
+	// A single failed dependency is still reported as a DependencyLoadError
+	loader, _ = New(Components{
+		"Database": func() (int, error) { return 0, databaseErr },
+		"Port":     func() int { return 80 },
+		"Server": func(options struct {
+			Port     int
+			Database int
+		}) int {
+			return 0
+		},
+	})
+	if _, err := loader.Load("Server"); err == nil {
+		t.Error("expected an error")
+	} else if _, ok := err.(*DependencyLoadError); !ok {
+		t.Error("expected a DependencyLoadError, got: ", err)
+	}
+}
+
+func TestDependencyLoadErrorAnnotations(t *testing.T) {
+	loader, _ := New(Components{
+		"Database": func() (int, error) {
+			time.Sleep(10 * time.Millisecond)
+			return 0, context.DeadlineExceeded
+		},
+		"Cache":  func() int { panic("out of memory") },
+		"Config": func() (int, error) { return 0, errors.New("missing file") },
+		"Users":  func(options struct{ Database int }) int { return options.Database },
+		"Server": func(options struct{ Users int }) int { return options.Users },
+		"Worker": func(options struct{ Cache int }) int { return options.Cache },
+		"Admin":  func(options struct{ Config int }) int { return options.Config },
+	})
+
+	_, err := loader.Load("Server")
+	t.Logf("got error as expected: '%s'", err)
+	e, ok := err.(*DependencyLoadError)
+	if !ok {
+		t.Fatal("expected a DependencyLoadError")
+	}
+	durations := e.Durations()
+	if len(durations) != 3 || durations[2] < 10*time.Millisecond || durations[0] < durations[2] {
+		t.Error("unexpected durations: ", durations)
+	}
+	if !strings.Contains(err.Error(), ", timeout): ") {
+		t.Error("expected the error to describe a timeout")
+	}
+
+	_, err = loader.Load("Worker")
+	t.Logf("got error as expected: '%s'", err)
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "out of memory" || len(panicErr.Stack) == 0 {
+		t.Error("expected a PanicError, got: ", err)
+	}
+	if !strings.Contains(err.Error(), ", panic): ") {
+		t.Error("expected the error to describe a panic")
+	}
+
+	_, err = loader.Load("Admin")
+	t.Logf("got error as expected: '%s'", err)
+	if !strings.Contains(err.Error(), ", error): missing file") {
+		t.Error("expected the error to describe an error")
+	}
+}
@@ -0,0 +1,211 @@
+// Package manifest builds components from a manifest describing the graph
+// topology, such that wiring can be rearranged per environment without
+// recompiling.
+//
+// A manifest names each component, the factory loading it and the components
+// bound to the dependency fields of the factory. Factories are ordinary
+// component functions registered by name:
+//
+//   {
+//     "components": {
+//       "Primary": {"factory": "postgres"},
+//       "Replica": {"factory": "postgres", "profiles": {"dev": {"factory": "sqlite"}}},
+//       "Users":   {"factory": "users", "dependencies": {"Database": "Replica"}}
+//     }
+//   }
+//
+//   components, err := manifest.Load("manifest.json", manifest.Factories{
+//       "postgres": NewPostgres,
+//       "sqlite":   NewSQLite,
+//       "users":    func(options struct{ Database *sql.DB }) *UserModel { ... },
+//   })
+//   loader := components.AsLoaderWithProfile(os.Getenv("PROFILE"))
+//
+// Only JSON files are supported, YAML and other formats can be decoded into a
+// Manifest and passed to Manifest.Bind().
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+// Factories maps factory names used in a manifest to component functions.
+type Factories map[string]interface{}
+
+// A Manifest describes the components of a graph.
+type Manifest struct {
+	Components map[string]Component `json:"components" yaml:"components"`
+}
+
+// A Component describes how a component is loaded, and how this differs for
+// each profile, see acyclicloader.Components.ForProfile().
+type Component struct {
+	Binding  `yaml:",inline"`
+	Profiles map[string]Binding `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+}
+
+// A Binding names the factory loading a component, and maps dependency fields
+// in the options struct of the factory to the components bound to them. Fields
+// not listed load the component named by the field, or its tag.
+type Binding struct {
+	Factory      string            `json:"factory,omitempty" yaml:"factory,omitempty"`
+	Dependencies map[string]string `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// Load reads the manifest at path and returns components bound to factories.
+// Only files with a .json extension are supported.
+func Load(path string, factories Factories) (acyclicloader.Components, error) {
+	if ext := filepath.Ext(path); ext != ".json" {
+		return nil, fmt.Errorf("unsupported manifest file format %q in %s", ext, path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	components, err := FromJSON(data, factories)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest file %s: %w", path, err)
+	}
+	return components, nil
+}
+
+// FromJSON returns components bound to factories, as described by the JSON
+// manifest in data. Unknown keys in data are reported as an error, as they
+// are most likely typos.
+func FromJSON(data []byte, factories Factories) (acyclicloader.Components, error) {
+	var m Manifest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m.Bind(factories)
+}
+
+// Bind returns components bound to factories, as described by m.
+//
+// The manifest is validated against factories, and an error listing all
+// problems is returned if a binding refers to an undefined factory, a
+// factory isn't a function, or a dependency isn't a field of the options
+// struct of the factory. Dependencies on undefined components and type
+// mismatches are reported when the loader is created.
+func (m *Manifest) Bind(factories Factories) (acyclicloader.Components, error) {
+	var problems []string
+	bind := func(name string, b Binding) interface{} {
+		if b.Factory == "" {
+			problems = append(problems, fmt.Sprintf("'%s' must have a factory", name))
+			return nil
+		}
+		fn, ok := factories[b.Factory]
+		if !ok {
+			problems = append(problems, fmt.Sprintf(
+				"'%s' uses undefined factory '%s' (available factories: '%s')",
+				name, b.Factory, strings.Join(sortedKeys(factories), "', '"),
+			))
+			return nil
+		}
+		if t := reflect.TypeOf(fn); t == nil || t.Kind() != reflect.Func {
+			problems = append(problems, fmt.Sprintf(
+				"factory '%s' for '%s' must be a function, but has type %T", b.Factory, name, fn,
+			))
+			return nil
+		}
+		var annotations []acyclicloader.Annotation
+		for _, field := range sortedKeys(b.Dependencies) {
+			if !hasField(fn, field) {
+				problems = append(problems, fmt.Sprintf(
+					"'%s' binds '%s' to field '%s', but factory '%s' has no such dependency",
+					name, b.Dependencies[field], field, b.Factory,
+				))
+				continue
+			}
+			annotations = append(annotations, acyclicloader.RenameDependency(field, b.Dependencies[field]))
+		}
+		if len(annotations) == 0 {
+			return fn
+		}
+		return acyclicloader.Annotate(fn, annotations...)
+	}
+
+	components := acyclicloader.Components{}
+	profiles := map[string]acyclicloader.Components{}
+	for _, name := range sortedKeys(m.Components) {
+		c := m.Components[name]
+		if c.Factory == "" && len(c.Profiles) == 0 {
+			problems = append(problems, fmt.Sprintf("'%s' must have a factory or profiles", name))
+			continue
+		}
+		if c.Factory != "" {
+			components[name] = bind(name, c.Binding)
+		}
+		for profile, b := range c.Profiles {
+			if b.Factory == "" {
+				// Profiles without a factory only rebind some dependencies
+				b = Binding{Factory: c.Factory, Dependencies: merge(c.Dependencies, b.Dependencies)}
+			}
+			if profiles[profile] == nil {
+				profiles[profile] = acyclicloader.Components{}
+			}
+			profiles[profile][name] = bind(fmt.Sprintf("%s (profile %s)", name, profile), b)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid manifest:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	for _, profile := range sortedKeys(profiles) {
+		components = components.ForProfile(profile, profiles[profile])
+	}
+	return components, nil
+}
+
+// merge returns a map with the entries from both base and overrides, with
+// entries from overrides taking precedence.
+func merge(base, overrides map[string]string) map[string]string {
+	result := make(map[string]string, len(base)+len(overrides))
+	for key, value := range base {
+		result[key] = value
+	}
+	for key, value := range overrides {
+		result[key] = value
+	}
+	return result
+}
+
+// hasField returns true, if the function fn takes an options struct with field.
+func hasField(fn interface{}, field string) bool {
+	t := reflect.TypeOf(fn)
+	if t.NumIn() != 1 {
+		return false
+	}
+	input := t.In(0)
+	if input.Kind() == reflect.Ptr {
+		input = input.Elem()
+	}
+	if input.Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := input.FieldByName(field)
+	return ok
+}
+
+// sortedKeys returns the keys of m sorted, m must be a map with string keys.
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.String()
+	}
+	sort.Strings(names)
+	return names
+}
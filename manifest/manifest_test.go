@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jonasfj/go-acyclicloader"
+)
+
+type database struct{ name string }
+
+var factories = Factories{
+	"postgres": func(options struct{ DSN string }) *database {
+		return &database{name: "postgres " + options.DSN}
+	},
+	"sqlite": func() *database { return &database{name: "sqlite"} },
+	"dsn":    func() string { return "db.example.com" },
+	"users": func(options struct{ Database *database }) string {
+		return "users in " + options.Database.name
+	},
+}
+
+const manifestJSON = `{
+  "components": {
+    "DSN":     {"factory": "dsn"},
+    "Primary": {"factory": "postgres"},
+    "Replica": {"factory": "postgres", "profiles": {"dev": {"factory": "sqlite"}}},
+    "Users":   {
+      "factory": "users",
+      "dependencies": {"Database": "Replica"},
+      "profiles": {"test": {"dependencies": {"Database": "Primary"}}}
+    }
+  }
+}`
+
+func TestFromJSON(t *testing.T) {
+	components, err := FromJSON([]byte(manifestJSON), factories)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	for profile, expected := range map[string]string{
+		"":     "users in postgres db.example.com",
+		"dev":  "users in sqlite",
+		"test": "users in postgres db.example.com",
+	} {
+		loader := components.AsLoaderWithProfile(profile)
+		if value := loader.MustLoad("Users"); value != expected {
+			t.Errorf("expected %q for profile %q, got: %v", expected, profile, value)
+		}
+		if profile == "test" && loader.Loaded("Replica") {
+			t.Error("expected 'Users' to depend on 'Primary' in the test profile")
+		}
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	m := &Manifest{Components: map[string]Component{
+		"A": {Binding: Binding{Factory: "missing"}},
+		"B": {Binding: Binding{Factory: "users", Dependencies: map[string]string{"DB": "A"}}},
+		"C": {},
+		"D": {Binding: Binding{Factory: "value"}},
+	}}
+	_, err := m.Bind(Factories{"users": factories["users"], "value": 42})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{
+		"'A' uses undefined factory 'missing'",
+		"field 'DB', but factory 'users' has no such dependency",
+		"'C' must have a factory",
+		"factory 'value' for 'D' must be a function",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q, got: %s", expected, err)
+		}
+	}
+
+	if _, err := FromJSON([]byte(`{"components": {"A": {"factroy": "dsn"}}}`), factories); err == nil {
+		t.Error("expected an error for unknown keys")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "manifest.json")
+	if err := ioutil.WriteFile(path, []byte(manifestJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	components, err := Load(path, factories)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if _, err := acyclicloader.New(components); err != nil {
+		t.Error("unexpected error: ", err)
+	}
+	if _, err := Load(filepath.Join(dir, "manifest.yaml"), factories); err == nil {
+		t.Error("expected an error for unsupported formats")
+	}
+}
@@ -0,0 +1,47 @@
+package acyclicloader
+
+import (
+	"context"
+	"path"
+)
+
+// LoadMatching loads all components with names matching pattern concurrently,
+// and returns their values indexed by name. Patterns use the syntax of
+// path.Match(), so "consumers/*" matches components mounted under "consumers",
+// see Components.Mount(). Keyed components are not loaded.
+//
+// This is useful for starting all registered consumers without listing them.
+// If one or more components fail to load, the values of the others are
+// returned along with the error from the alphabetically first that failed.
+// An error is returned if pattern is malformed.
+func (a *AcyclicLoader) LoadMatching(pattern string) (map[string]interface{}, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	a.m.Lock()
+	var names []string
+	for _, name := range sortedKeys(a.components) {
+		if matched, _ := path.Match(pattern, name); matched && !a.components[name].keyed {
+			names = append(names, name)
+		}
+	}
+	a.m.Unlock()
+
+	for _, name := range names {
+		a.goLoad(context.Background(), name)
+	}
+	values := make(map[string]interface{}, len(names))
+	var err error
+	for _, name := range names {
+		value, loadErr := a.Load(name)
+		if loadErr != nil {
+			if err == nil {
+				err = loadErr
+			}
+			continue
+		}
+		values[name] = value
+	}
+	return values, err
+}
@@ -0,0 +1,63 @@
+package acyclicloader
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadMatching(t *testing.T) {
+	failure := errors.New("queue unavailable")
+	loader := Components{
+		"consumers/Orders":   func() string { return "orders" },
+		"consumers/Invoices": func() string { return "invoices" },
+		"consumers/Emails":   func() (string, error) { return "", failure },
+		"producers/Orders":   func() string { return "producer" },
+	}.AsLoader()
+
+	values, err := loader.LoadMatching("consumers/*")
+	if !errors.Is(err, failure) {
+		t.Error("expected the error from 'consumers/Emails', got: ", err)
+	}
+	if len(values) != 2 || values["consumers/Orders"] != "orders" || values["consumers/Invoices"] != "invoices" {
+		t.Error("unexpected values: ", values)
+	}
+	if loader.Loaded("producers/Orders") {
+		t.Error("expected components not matching to be left unloaded")
+	}
+
+	if _, err := loader.LoadMatching("consumers/["); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
+
+func TestLoadMatchingLoadsOnce(t *testing.T) {
+	var m sync.Mutex
+	loads := map[string]int{}
+	count := func(name string) string {
+		time.Sleep(time.Millisecond) // give other goroutines a chance to load it
+		m.Lock()
+		defer m.Unlock()
+		loads[name]++
+		return name
+	}
+	loader := Components{
+		"Queue": func() string { return count("Queue") },
+		"consumers/Orders": func(options struct{ Queue string }) string {
+			return count("consumers/Orders")
+		},
+		"consumers/Invoices": func(options struct{ Queue string }) string {
+			return count("consumers/Invoices")
+		},
+	}.AsLoader()
+
+	if _, err := loader.LoadMatching("consumers/*"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	m.Lock()
+	defer m.Unlock()
+	if loads["Queue"] != 1 || loads["consumers/Orders"] != 1 || loads["consumers/Invoices"] != 1 {
+		t.Error("expected each component to be loaded once, got: ", loads)
+	}
+}
@@ -0,0 +1,67 @@
+package acyclicloader
+
+import "sort"
+
+type mergeStrategy int
+
+const (
+	failOnConflict mergeStrategy = iota
+	preferLeft
+	preferRight
+)
+
+// A MergeOption configures how Components.Merge() resolves components defined
+// in both sets.
+type MergeOption func(*mergeStrategy)
+
+// PreferLeft returns a MergeOption that resolves conflicts in
+// Components.Merge() by keeping the definition from the receiver.
+func PreferLeft() MergeOption {
+	return func(s *mergeStrategy) {
+		*s = preferLeft
+	}
+}
+
+// PreferRight returns a MergeOption that resolves conflicts in
+// Components.Merge() by using the definition from the other set.
+func PreferRight() MergeOption {
+	return func(s *mergeStrategy) {
+		*s = preferRight
+	}
+}
+
+// Merge returns a new set of components holding the components from both c and
+// other, neither c nor other is modified. This is useful when the components
+// for an application are defined in multiple packages.
+//
+// By default a MergeConflictError is returned if a component is defined in
+// both sets, see PreferLeft() and PreferRight() for resolving conflicts.
+func (c Components) Merge(other Components, options ...MergeOption) (Components, error) {
+	strategy := failOnConflict
+	for _, option := range options {
+		option(&strategy)
+	}
+
+	result := make(Components, len(c)+len(other))
+	for name, fn := range c {
+		result[name] = fn
+	}
+	var conflicts []string
+	for name, fn := range other {
+		if _, ok := result[name]; ok {
+			switch strategy {
+			case failOnConflict:
+				conflicts = append(conflicts, name)
+				continue
+			case preferLeft:
+				continue
+			}
+		}
+		result[name] = fn
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, &MergeConflictError{Components: conflicts}
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package acyclicloader
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	left := Components{
+		"A": func() int { return 1 },
+		"B": func() int { return 2 },
+	}
+	right := Components{
+		"B": func() int { return 3 },
+		"C": func(options struct{ A, B int }) int { return options.A + options.B },
+	}
+
+	_, err := left.Merge(right)
+	t.Logf("got error as expected: '%s'", err)
+	if e, ok := err.(*MergeConflictError); !ok || len(e.Components) != 1 {
+		t.Error("expected a MergeConflictError")
+	}
+
+	merged, err := left.Merge(right, PreferLeft())
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if merged.MustLoad("C").(int) != 3 {
+		t.Error("expected 3")
+	}
+
+	merged, _ = left.Merge(right, PreferRight())
+	if merged.MustLoad("C").(int) != 4 {
+		t.Error("expected 4")
+	}
+	if len(left) != 2 || len(right) != 2 {
+		t.Error("expected Merge not to modify its arguments")
+	}
+}
@@ -0,0 +1,39 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MetricsHandler returns an http.Handler serving a gauge per component in the
+// Prometheus text format, which is 1 when the component is loaded and healthy,
+// and 0 when it failed, is unhealthy or isn't loaded, such as after Shutdown().
+//
+//   http.Handle("/metrics/components", loader.MetricsHandler())
+//
+// Health is checked on each request like in Supervise(), using the methods
+// Failed() or HealthCheck() if implemented by the value. Keyed and transient
+// components are not included.
+//
+//   # TYPE acyclicloader_component_up gauge
+//   acyclicloader_component_up{component="Database"} 1
+func (a *AcyclicLoader) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		b.WriteString("# HELP acyclicloader_component_up Whether the component is loaded and healthy.\n")
+		b.WriteString("# TYPE acyclicloader_component_up gauge\n")
+		for _, h := range a.health(r.Context()) {
+			up := 0
+			if h.Healthy {
+				up = 1
+			}
+			fmt.Fprintf(&b, "acyclicloader_component_up{component=\"%s\"} %d\n", labelEscaper.Replace(h.Component), up)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(b.String()))
+	})
+}
+
+// labelEscaper escapes label values in the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,57 @@
+package acyclicloader
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type healthChecked struct {
+	err error
+}
+
+func (h *healthChecked) HealthCheck(context.Context) error {
+	return h.err
+}
+
+func TestMetricsHandler(t *testing.T) {
+	loader := Components{
+		"Database":  func() *healthChecked { return &healthChecked{} },
+		"Cache":     func() *healthChecked { return &healthChecked{err: errors.New("timeout")} },
+		"Queue":     func() (int, error) { return 0, errors.New("connection refused") },
+		"Unused":    func() int { return 1 },
+		`Quoted"Id`: func() int { return 1 },
+	}.AsLoader()
+	loader.Load("Database")
+	loader.Load("Cache")
+	loader.Load("Queue")
+	loader.Load(`Quoted"Id`)
+
+	scrape := func() string {
+		w := httptest.NewRecorder()
+		loader.MetricsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		body, _ := ioutil.ReadAll(w.Body)
+		return string(body)
+	}
+	body := scrape()
+	for _, line := range []string{
+		"# TYPE acyclicloader_component_up gauge",
+		`acyclicloader_component_up{component="Cache"} 0`,
+		`acyclicloader_component_up{component="Database"} 1`,
+		`acyclicloader_component_up{component="Queue"} 0`,
+		`acyclicloader_component_up{component="Quoted\"Id"} 1`,
+		`acyclicloader_component_up{component="Unused"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected %q in:\n%s", line, body)
+		}
+	}
+
+	loader.Shutdown()
+	if body := scrape(); !strings.Contains(body, `acyclicloader_component_up{component="Database"} 0`) {
+		t.Error("expected components to be down after shutdown, got: ", body)
+	}
+}
@@ -0,0 +1,28 @@
+package acyclicloader
+
+// A LoadFunc loads a component, returning its value or an error.
+type LoadFunc func() (interface{}, error)
+
+// A Middleware wraps the LoadFunc loading a component, and is given the name
+// of the component being loaded. Middleware can be used to implement timing,
+// retries, tracing or error translation for all components uniformly.
+//
+// The value returned from a Middleware must have the type of the component,
+// unless an error is returned, otherwise loading the component fails with a
+// MiddlewareResultError.
+type Middleware func(component string, next LoadFunc) LoadFunc
+
+// WithMiddleware returns a clone of the AcyclicLoader, which calls the
+// function loading each component through middleware.
+//
+// If middleware is added multiple times, the middleware added last will be
+// the outermost middleware.
+func (a *AcyclicLoader) WithMiddleware(middleware Middleware) *AcyclicLoader {
+	option := func(a *AcyclicLoader) {
+		a.middleware = append(a.middleware[:len(a.middleware):len(a.middleware)], middleware)
+	}
+	a2 := a.Clone()
+	a2.options = append(a.options[:len(a.options):len(a.options)], option)
+	option(a2)
+	return a2
+}
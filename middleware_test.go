@@ -0,0 +1,80 @@
+package acyclicloader
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	loader, _ := New(Components{
+		"A": func() int { return 1 },
+		"B": func(options struct{ A int }) int { return options.A + 1 },
+		"C": func(options struct{ A, B int }) (int, error) {
+			return 0, errors.New("failed")
+		},
+	})
+
+	var m sync.Mutex
+	var loaded []string
+	logging := loader.WithMiddleware(func(component string, next LoadFunc) LoadFunc {
+		return func() (interface{}, error) {
+			m.Lock()
+			loaded = append(loaded, component)
+			m.Unlock()
+			return next()
+		}
+	})
+	translating := logging.WithMiddleware(func(component string, next LoadFunc) LoadFunc {
+		return func() (interface{}, error) {
+			v, err := next()
+			if err != nil {
+				err = fmt.Errorf("component %s: %s", component, err)
+			}
+			return v, err
+		}
+	})
+
+	_, err := translating.Load("C")
+	if err == nil || err.Error() != "component C: failed" {
+		t.Error("expected translated error, got: ", err)
+	}
+	sort.Strings(loaded)
+	if strings.Join(loaded, ",") != "A,B,C" {
+		t.Error("expected A, B and C to be loaded through middleware, got: ", loaded)
+	}
+
+	// Middleware isn't applied to the original loader
+	loaded = nil
+	if loader.MustLoad("B").(int) != 2 {
+		t.Error("expected 2")
+	}
+	if len(loaded) != 0 {
+		t.Error("expected middleware not to be called")
+	}
+}
+
+func TestMiddlewareResultType(t *testing.T) {
+	loader := Components{
+		"Port":   func() int { return 80 },
+		"Server": func(options struct{ Port int }) string { return fmt.Sprint(options.Port) },
+	}.AsLoader().WithMiddleware(func(component string, next LoadFunc) LoadFunc {
+		return func() (interface{}, error) {
+			v, err := next()
+			if component == "Port" {
+				return "80", err
+			}
+			return v, err
+		}
+	})
+
+	_, err := loader.Load("Server")
+	t.Logf("got error as expected: '%s'", err)
+	var resultErr *MiddlewareResultError
+	if !errors.As(err, &resultErr) || resultErr.Component != "Port" {
+		t.Error("expected a MiddlewareResultError for 'Port', got: ", err)
+	}
+}
@@ -0,0 +1,152 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Mount returns a new set of components holding the components from c and the
+// components from sub, with the components from sub renamed to prefix/Name.
+// Neither c nor sub is modified.
+//
+// Dependencies between components in sub are rewritten to refer to the renamed
+// components, while dependencies on components not defined in sub are left
+// as-is, allowing sub to depend on components defined in c. This allows a
+// library to ship a set of components without name collisions. Dependencies
+// wired by type, such as plain parameters, are wired to the component in sub
+// of that type, if any, and a ComponentDefinitionError is returned if several
+// components in sub have the type.
+//
+//   components, err := acyclicloader.Components{
+//       "Database": func() *sql.DB { ... },
+//   }.Mount("auth", auth.Components) // "auth/Users" may depend on "Database"
+//
+// A MergeConflictError is returned, if c already defines a component with the
+// same name as a renamed component from sub.
+func (c Components) Mount(prefix string, sub Components) (Components, error) {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+
+	names := make([]string, 0, len(sub))
+	for name := range sub {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Result types of components in sub, for dependencies wired by type
+	results := map[string][]reflect.Type{}
+	for name, fn := range sub {
+		variants := []interface{}{fn}
+		if p, ok := fn.(*profiled); ok {
+			variants = p.variants()
+		}
+		for _, variant := range variants {
+			if c, err := newComponent(name, variant); err == nil && c.result != nil && !c.keyed {
+				results[name] = append(results[name], c.result)
+			}
+		}
+	}
+
+	// mountDefinition wraps fn with an annotation renaming its dependencies
+	var err error
+	mountDefinition := func(name string) func(fn interface{}) interface{} {
+		return func(fn interface{}) interface{} {
+			// Rewrite dependencies on components from sub
+			renamed := map[string]string{}
+			for _, dep := range definedDependencies(fn) {
+				if dep.byType != nil {
+					// Dependencies wired by type are renamed, if provided by sub
+					var matches []string
+					for _, candidate := range names {
+						if candidate != name && anyAssignableTo(results[candidate], dep.byType) {
+							matches = append(matches, candidate)
+						}
+					}
+					if len(matches) > 1 && err == nil {
+						err = &ComponentDefinitionError{
+							Component: prefix + name,
+							message: fmt.Sprintf(
+								"cannot mount '%s', type %s of '%s' is provided by components '%s'",
+								name, dep.byType.String(), dep.field, strings.Join(matches, "', '"),
+							),
+						}
+					}
+					if len(matches) == 1 {
+						renamed[dep.field] = prefix + matches[0]
+					}
+					continue // otherwise wired to a component outside sub
+				}
+				if dep.field == "" {
+					continue // feature flags and ordering constraints are renamed by the mount annotation
+				}
+				if _, ok := sub[dep.component]; ok {
+					renamed[dep.field] = prefix + dep.component
+				} else {
+					renamed[dep.field] = dep.component
+				}
+			}
+			var annotations []Annotation
+			if an, ok := fn.(*Annotated); ok {
+				fn = an.fn
+				annotations = an.annotations
+			}
+			return Annotate(fn, append(
+				annotations[:len(annotations):len(annotations)],
+				mount(prefix, sub, renamed),
+			)...)
+		}
+	}
+
+	mounted := make(Components, len(sub))
+	for _, name := range names {
+		fn := sub[name]
+		if p, ok := fn.(*profiled); ok {
+			mounted[prefix+name] = p.mapVariants(mountDefinition(name))
+		} else {
+			mounted[prefix+name] = mountDefinition(name)(fn)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Merge(mounted)
+}
+
+// anyAssignableTo returns true, if any of types is assignable to t.
+func anyAssignableTo(types []reflect.Type, t reflect.Type) bool {
+	for _, candidate := range types {
+		if candidate.AssignableTo(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// mount returns an Annotation that renames dependencies according to renamed,
+// and rewrites other annotations referring to components in sub.
+func mount(prefix string, sub Components, renamed map[string]string) Annotation {
+	return func(c *component) {
+		c.renamed = renamed
+		acknowledged := make([]string, len(c.acknowledged))
+		for i, dep := range c.acknowledged {
+			if _, ok := sub[dep]; ok {
+				dep = prefix + dep
+			}
+			acknowledged[i] = dep
+		}
+		c.acknowledged = acknowledged
+		if _, ok := sub[c.enabledBy]; ok {
+			c.enabledBy = prefix + c.enabledBy
+		}
+		after := make([]string, len(c.after))
+		for i, dep := range c.after {
+			if _, ok := sub[dep]; ok {
+				dep = prefix + dep
+			}
+			after[i] = dep
+		}
+		c.after = after
+	}
+}
@@ -0,0 +1,124 @@
+package acyclicloader
+
+import "testing"
+
+func TestMount(t *testing.T) {
+	auth := Components{
+		"Secret": func() string { return "secret" },
+		"Users": func(options struct {
+			Secret   string
+			Database string
+		}) string {
+			return options.Database + ":" + options.Secret
+		},
+	}
+	components, err := Components{
+		"Database": func() string { return "db" },
+		"Secret":   func() string { return "other" },
+	}.Mount("auth", auth)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if components.MustLoad("auth/Users").(string) != "db:secret" {
+		t.Error("expected 'db:secret'")
+	}
+
+	// Mounting twice with the same prefix conflicts
+	_, err = components.Mount("auth/", auth)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	// Mounting a mounted set of components
+	inner, _ := Components{}.Mount("app", auth)
+	nested, err := Components{
+		"Database": func() string { return "db" },
+	}.Mount("outer", inner)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if nested.MustLoad("outer/app/Users").(string) != "db:secret" {
+		t.Error("expected 'db:secret'")
+	}
+}
+
+func TestMountOptionalIntegration(t *testing.T) {
+	library := Components{
+		"Notifier": func(options struct {
+			Slack string `component:",optional"`
+		}) string {
+			if options.Slack == "" {
+				return "log"
+			}
+			return options.Slack
+		},
+	}
+
+	components, err := Components{}.Mount("lib", library)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if value := components.AsLoader().MustLoad("lib/Notifier"); value != "log" {
+		t.Error("expected integration to be absent, got: ", value)
+	}
+
+	components, err = Components{
+		"Slack": func() string { return "slack" },
+	}.Mount("lib", library)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if value := components.AsLoader().MustLoad("lib/Notifier"); value != "slack" {
+		t.Error("expected integration defined by the host to be used, got: ", value)
+	}
+}
+
+type mountSecret string
+
+func TestMountResolvedDependencies(t *testing.T) {
+	auth := Components{
+		"Secret": func() mountSecret { return "secret" },
+		"Token": func(secret mountSecret) string {
+			return "token:" + string(secret)
+		},
+		"Session": Annotate(func(user string, options struct{ Secret mountSecret }) string {
+			return user + ":" + string(options.Secret)
+		}, Keyed()),
+		"Hasher": func(options struct {
+			Secret mountSecret `component:",bytype"`
+		}) int {
+			return len(options.Secret)
+		},
+	}
+	components, err := Components{
+		"Secret": func() mountSecret { return "other" },
+	}.Mount("auth", auth)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	loader, err := New(components)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if v := loader.MustLoad("auth/Token").(string); v != "token:secret" {
+		t.Errorf("expected plain parameters to be wired within the mount, got '%s'", v)
+	}
+	if v, err := loader.LoadKeyed("auth/Session", "alice"); err != nil || v.(string) != "alice:secret" {
+		t.Errorf("expected keyed dependencies to be wired within the mount, got '%v', %v", v, err)
+	}
+	if v := loader.MustLoad("auth/Hasher").(int); v != 6 {
+		t.Errorf("expected fields wired by type to be wired within the mount, got %d", v)
+	}
+
+	// Types provided by several components in sub are ambiguous
+	_, err = Components{}.Mount("auth", Components{
+		"Primary":   func() mountSecret { return "a" },
+		"Secondary": func() mountSecret { return "b" },
+		"Token":     func(secret mountSecret) string { return string(secret) },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*ComponentDefinitionError); !ok {
+		t.Error("expected a ComponentDefinitionError")
+	}
+}
@@ -0,0 +1,147 @@
+package acyclicloader
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// A Logger is used by an AcyclicLoader to report warnings, *log.Logger
+// satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+var defaultLogger Logger = log.New(os.Stderr, "acyclicloader: ", log.LstdFlags)
+
+// An Option configures an AcyclicLoader, options can be given to New() or
+// Components.AsLoader().
+type Option func(*AcyclicLoader)
+
+// WithLogger returns an Option that makes the AcyclicLoader report warnings
+// to logger, by default warnings are written to stderr.
+func WithLogger(logger Logger) Option {
+	return func(a *AcyclicLoader) {
+		a.logger = logger
+	}
+}
+
+// WithStrictMode returns an Option that makes New() return an error where it
+// would otherwise report a warning.
+func WithStrictMode() Option {
+	return func(a *AcyclicLoader) {
+		a.strict = true
+	}
+}
+
+// WithRoots returns an Option that makes New() report a warning for components
+// not reachable from any of the given roots, see AcyclicLoader.Unreachable().
+// Combined with WithStrictMode(), New() returns an error instead, such that
+// dead components are caught by tests.
+func WithRoots(roots ...string) Option {
+	return func(a *AcyclicLoader) {
+		a.roots = roots
+	}
+}
+
+// WithAutoWiring returns an Option that makes New() wire dependencies that
+// don't match the name of any component, to the unique component assignable to
+// the type of the field. New() returns an error, if more than one component is
+// assignable to the type of such a field.
+//
+//   "Server": func(options struct {
+//       DB *sql.DB // wired to "Database", if it is the only *sql.DB
+//   }) *Server { ... },
+func WithAutoWiring() Option {
+	return func(a *AcyclicLoader) {
+		a.autoWire = true
+	}
+}
+
+// WithProfile returns an Option that makes New() select definitions for the
+// given profile, see Components.ForProfile().
+func WithProfile(profile string) Option {
+	return func(a *AcyclicLoader) {
+		a.profile = profile
+	}
+}
+
+// WithMetadata returns an Option that makes metadata available to components
+// through the Info field in their options struct.
+func WithMetadata(metadata map[string]string) Option {
+	return func(a *AcyclicLoader) {
+		a.metadata = metadata
+	}
+}
+
+// WithGracePeriod returns an Option that makes RunUntilSignal() wait up to d
+// for components to shut down gracefully, by default this is 30 seconds.
+func WithGracePeriod(d time.Duration) Option {
+	return func(a *AcyclicLoader) {
+		a.gracePeriod = d
+	}
+}
+
+// WithSupervisorBackoff returns an Option that makes Supervise() wait between
+// attempts to restart a component according to backoff, by default the delay
+// starts at twice the interval given to Supervise() and doubles up to 32
+// intervals.
+func WithSupervisorBackoff(backoff Backoff) Option {
+	return func(a *AcyclicLoader) {
+		a.backoff = backoff
+	}
+}
+
+// WithSlowThreshold returns an Option that makes the AcyclicLoader report a
+// warning when the function loading a component is still running after d, and
+// again each time the duration doubles. The warning lists components blocked
+// waiting for the component, answering what a slow startup is stuck on.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(a *AcyclicLoader) {
+		a.slow = d
+	}
+}
+
+// WithSemaphore returns an Option that defines a semaphore allowing at most n
+// functions loading components limited by the semaphore to run concurrently,
+// see LimitedBy(). This is useful for avoiding rate limits of external APIs.
+// Values of n less than 1 are treated as 1.
+func WithSemaphore(name string, n int) Option {
+	if n < 1 {
+		n = 1
+	}
+	return func(a *AcyclicLoader) {
+		semaphores := make(map[string]chan struct{}, len(a.semaphores)+1)
+		for s, sem := range a.semaphores {
+			semaphores[s] = sem
+		}
+		semaphores[name] = make(chan struct{}, n)
+		a.semaphores = semaphores
+	}
+}
+
+// WithMaxConcurrency returns an Option that allows at most n functions loading
+// components to run concurrently. When more functions are ready to run, those
+// with the highest priority run first, see Priority(). Values of n less than 1
+// are treated as 1.
+//
+// A function loading a component must not call Load() for components not yet
+// loaded, as this may deadlock when all n functions are waiting.
+func WithMaxConcurrency(n int) Option {
+	if n < 1 {
+		n = 1
+	}
+	return func(a *AcyclicLoader) {
+		a.scheduler = &scheduler{free: n}
+	}
+}
+
+// WithNilResultsAsErrors returns an Option that makes loading a component fail
+// with a NilResultError, if the function loading it returns a nil pointer,
+// interface, map, channel or function without an error. This catches nil values
+// before they cause panics in dependents, see AllowNil() for exceptions.
+func WithNilResultsAsErrors() Option {
+	return func(a *AcyclicLoader) {
+		a.rejectNil = true
+	}
+}
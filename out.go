@@ -0,0 +1,120 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Out can be embedded in a struct returned by the function loading a component,
+// such that each exported field of the struct becomes a component, depending on
+// the component returning the struct. The component is named after the field,
+// unless overwritten by a `component:"Name"` tag, fields tagged with
+// `component:"-"` are skipped.
+//
+//   type Clients struct {
+//       acyclicloader.Out
+//       S3       *s3.Client
+//       SQS      *sqs.Client
+//       DynamoDB *dynamodb.Client `component:"Dynamo"`
+//   }
+//
+//   "Clients": func(options struct{ Config *aws.Config }) (*Clients, error) {
+//       ...
+//   },
+//
+// Components defined explicitly take precedence over fields with the same name,
+// which allows a single field to be overwritten.
+type Out struct{}
+
+var typeOfOut = reflect.TypeOf(Out{})
+
+// outFields returns the fields of t, which become components because t is a
+// struct or pointer to a struct embedding Out, indexed by component name.
+func outFields(t reflect.Type) map[string]reflect.StructField {
+	s := optionsStruct(t)
+	if s == nil {
+		return nil
+	}
+	embedsOut := false
+	for i := 0; i < s.NumField(); i++ {
+		if f := s.Field(i); f.Anonymous && f.Type == typeOfOut {
+			embedsOut = true
+		}
+	}
+	if !embedsOut {
+		return nil
+	}
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < s.NumField(); i++ {
+		field := s.Field(i)
+		if field.PkgPath != "" || field.Type == typeOfOut || field.Tag.Get("component") == "-" {
+			continue
+		}
+		name, _ := parseComponentTag(field)
+		fields[name] = field
+	}
+	return fields
+}
+
+// addOutputs adds a component for each field of results embedding Out, and
+// returns the names of components added. Must be called while creating a.
+func (a *AcyclicLoader) addOutputs(names []string) ([]string, error) {
+	var added []string
+	for _, source := range names {
+		c := a.components[source]
+		if c.keyed || c.result == nil {
+			continue
+		}
+		fields := outFields(c.result)
+		for _, name := range sortedStrings(keysOf(fields)) {
+			if _, ok := a.definitions[name]; ok {
+				continue // explicit definitions take precedence
+			}
+			if other, ok := a.components[name]; ok {
+				return nil, &ComponentDefinitionError{
+					Component: name,
+					message: fmt.Sprintf(
+						"component '%s' is provided by both '%s' and '%s'", name, other.output, source,
+					),
+				}
+			}
+			fc, err := newComponent(name, outputFunc(source, c.result, fields[name]))
+			if err != nil {
+				return nil, err
+			}
+			fc.output = source
+			a.components[name] = fc
+			added = append(added, name)
+		}
+	}
+	return added, nil
+}
+
+// outputFunc returns a function loading field from the result of the source
+// component, which has type result.
+func outputFunc(source string, result reflect.Type, field reflect.StructField) interface{} {
+	options := reflect.StructOf([]reflect.StructField{{
+		Name: "Source",
+		Type: result,
+		Tag:  reflect.StructTag(fmt.Sprintf(`component:"%s"`, source)),
+	}})
+	fnType := reflect.FuncOf([]reflect.Type{options}, []reflect.Type{field.Type}, false)
+	return reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		v := args[0].Field(0)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return []reflect.Value{reflect.Zero(field.Type)}
+			}
+			v = v.Elem()
+		}
+		return []reflect.Value{v.FieldByIndex(field.Index)}
+	}).Interface()
+}
+
+func keysOf(fields map[string]reflect.StructField) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	return keys
+}
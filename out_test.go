@@ -0,0 +1,73 @@
+package acyclicloader
+
+import (
+	"strings"
+	"testing"
+)
+
+type testClients struct {
+	Out
+	S3       string
+	SQS      string
+	DynamoDB string `component:"Dynamo"`
+	Internal string `component:"-"`
+	secret   string
+}
+
+func TestOut(t *testing.T) {
+	loads := 0
+	components := Components{
+		"Region": "eu-west-1",
+		"Clients": func(options struct{ Region string }) *testClients {
+			loads++
+			return &testClients{
+				S3:       "s3." + options.Region,
+				SQS:      "sqs." + options.Region,
+				DynamoDB: "dynamodb." + options.Region,
+			}
+		},
+		"Uploader": func(options struct{ S3 string }) string { return "uploader:" + options.S3 },
+	}
+	loader, err := New(components, WithRoots("Uploader"))
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	names := strings.Join(loader.ComponentNames(), ", ")
+	if names != "Clients, Dynamo, Region, S3, SQS, Uploader" {
+		t.Error("unexpected components: ", names)
+	}
+	if loader.MustLoad("Uploader").(string) != "uploader:s3.eu-west-1" {
+		t.Error("expected 'uploader:s3.eu-west-1'")
+	}
+	if loader.MustLoad("Dynamo").(string) != "dynamodb.eu-west-1" || loads != 1 {
+		t.Error("expected fields to be loaded from a single call")
+	}
+	if unreachable := loader.Unreachable("Uploader"); len(unreachable) != 0 {
+		t.Error("expected fields of 'Clients' to be reachable, got: ", unreachable)
+	}
+
+	// Explicit definitions take precedence over fields
+	components["S3"] = func() string { return "mock" }
+	loader = components.AsLoader()
+	if loader.MustLoad("Uploader").(string) != "uploader:mock" {
+		t.Error("expected 'uploader:mock'")
+	}
+	loader, err = loader.WithOverwriteFuncs(map[string]interface{}{
+		"SQS": func() string { return "mock" },
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("SQS").(string) != "mock" {
+		t.Error("expected 'mock'")
+	}
+
+	_, err = New(Components{
+		"Clients": func() testClients { return testClients{} },
+		"Backup":  func() *testClients { return nil },
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "provided by both") {
+		t.Error("expected an error for fields provided twice")
+	}
+}
@@ -0,0 +1,70 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// hasPlainParams returns true, if the function type t takes plain parameters
+// wired by type, rather than an options struct. The first skip parameters, such
+// as the key of a keyed component, are not considered.
+func hasPlainParams(t reflect.Type, skip int) bool {
+	if t.IsVariadic() {
+		return false
+	}
+	switch t.NumIn() - skip {
+	case 0:
+		return false
+	case 1:
+		return optionsStruct(t.In(skip)) == nil
+	}
+	return true
+}
+
+// wireByType returns a function calling fn, taking an options struct with a
+// field for each plain parameter of fn, such that parameters are wired to the
+// unique component assignable to their type. The first skip parameters are
+// passed through.
+func wireByType(fn reflect.Value, skip int) reflect.Value {
+	t := fn.Type()
+	fields := make([]reflect.StructField, t.NumIn()-skip)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: paramName(i),
+			Type: t.In(skip + i),
+			Tag:  `component:",bytype"`,
+		}
+	}
+	in := make([]reflect.Type, skip, skip+1)
+	for i := range in {
+		in[i] = t.In(i)
+	}
+	in = append(in, reflect.StructOf(fields))
+	out := make([]reflect.Type, t.NumOut())
+	for i := range out {
+		out[i] = t.Out(i)
+	}
+	return reflect.MakeFunc(reflect.FuncOf(in, out, false), func(args []reflect.Value) []reflect.Value {
+		params := append(make([]reflect.Value, 0, t.NumIn()), args[:skip]...)
+		options := args[skip]
+		for i := range fields {
+			params = append(params, options.Field(i))
+		}
+		return fn.Call(params)
+	})
+}
+
+// paramName returns the name of the field for the i'th plain parameter wired
+// by wireByType(), which may be renamed by RenameDependency().
+func paramName(i int) string {
+	return fmt.Sprintf("Param%d", i)
+}
+
+// plainParamsFunc returns fn with plain parameters wrapped by wireByType(), or
+// fn as is, if it takes an options struct.
+func plainParamsFunc(fn interface{}) interface{} {
+	if t := reflect.TypeOf(fn); t != nil && t.Kind() == reflect.Func && hasPlainParams(t, 0) {
+		return wireByType(reflect.ValueOf(fn), 0).Interface()
+	}
+	return fn
+}
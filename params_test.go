@@ -0,0 +1,82 @@
+package acyclicloader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPlainParams(t *testing.T) {
+	type Database struct{ name string }
+	loader, err := New(Components{
+		"Port":     80,
+		"Database": func() *Database { return &Database{name: "db"} },
+		"Address": func(ctx context.Context, port int, db *Database) string {
+			return fmt.Sprintf("%s:%d", db.name, port)
+		},
+		"Length": func(address string) (uint, error) { return uint(len(address)), nil },
+		"Server": func(options struct {
+			Addr string `component:",bytype"`
+		}) []byte {
+			return []byte(options.Addr)
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("Address").(string) != "db:80" {
+		t.Error("expected 'db:80'")
+	}
+	if loader.MustLoad("Length").(uint) != 5 {
+		t.Error("expected 5")
+	}
+	if string(loader.MustLoad("Server").([]byte)) != "db:80" {
+		t.Error("expected 'db:80'")
+	}
+	if deps := strings.Join(loader.Dependencies("Address"), ", "); deps != "Database, Port" {
+		t.Error("unexpected dependencies: ", deps)
+	}
+
+	decorated, err := loader.Decorate("Address", func(address string) string {
+		return "http://" + address
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if decorated.MustLoad("Address").(string) != "http://db:80" {
+		t.Error("expected 'http://db:80'")
+	}
+}
+
+func TestPlainParamsErrors(t *testing.T) {
+	_, err := New(Components{
+		"Address": func(port int, host string) string { return "" },
+		"Host":    "localhost",
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "no such component") {
+		t.Error("expected an error for a parameter without a matching component")
+	}
+
+	_, err = New(Components{
+		"Address": func(port int) string { return "" },
+		"Port":    80,
+		"Backup":  8080,
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil || !strings.Contains(err.Error(), "'Backup', 'Port'") {
+		t.Error("expected an error for a parameter matching multiple components")
+	}
+}
+
+func TestParamsByType(t *testing.T) {
+	type Database struct{ name string }
+	loader := Components{
+		"Database": func() *Database { return &Database{name: "db"} },
+		"Users":    Annotate(func(db *Database) string { return db.name }, ParamsByType()),
+	}.AsLoader()
+	if loader.MustLoad("Users").(string) != "db" {
+		t.Error("expected 'db'")
+	}
+}
@@ -0,0 +1,56 @@
+package acyclicloader
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// callPool calls the function loading pooled component c concurrently for
+// each instance in the pool, returning the first error encountered, if any.
+func (c *component) callPool(in []reflect.Value) (interface{}, error) {
+	values := reflect.MakeSlice(c.result, c.pool, c.pool)
+	errs := make([]error, c.pool)
+	var wg sync.WaitGroup
+	wg.Add(c.pool)
+	for i := 0; i < c.pool; i++ {
+		go func(i int) {
+			defer wg.Done()
+			value, err := callRecover(c.fn, in, true)
+			values.Index(i).Set(valueOf(c.element, value))
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			// Close instances loaded successfully, as they are discarded
+			for i := range errs {
+				if closer, ok := values.Index(i).Interface().(io.Closer); ok && errs[i] == nil {
+					closer.Close()
+				}
+			}
+			return nil, err
+		}
+	}
+	return values.Interface(), nil
+}
+
+// isPoolAccessorFor returns true, if t is a function type on the form
+// func() T, where element is assignable to T.
+func isPoolAccessorFor(t, element reflect.Type) bool {
+	return t.Kind() == reflect.Func && t.NumIn() == 0 && t.NumOut() == 1 &&
+		element.AssignableTo(t.Out(0))
+}
+
+// poolAccessor returns a function of type t, on the form func() T, returning
+// the instances from pool in round-robin order.
+func poolAccessor(t reflect.Type, pool interface{}) reflect.Value {
+	instances := reflect.ValueOf(pool)
+	var next uint32
+	return reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+		i := int(atomic.AddUint32(&next, 1)-1) % instances.Len()
+		return []reflect.Value{valueOf(t.Out(0), instances.Index(i).Interface())}
+	})
+}
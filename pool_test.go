@@ -0,0 +1,69 @@
+package acyclicloader
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	var connections int32
+	loader := Components{
+		"Connections": Annotate(func() int32 {
+			return atomic.AddInt32(&connections, 1)
+		}, Pool(3)),
+		"All": func(options struct{ Connections []int32 }) int {
+			return len(options.Connections)
+		},
+		"Client": func(options struct{ Connections func() int32 }) func() int32 {
+			return options.Connections
+		},
+	}.AsLoader()
+	if loader.MustLoad("All").(int) != 3 {
+		t.Error("expected a slice of 3 instances")
+	}
+	next := loader.MustLoad("Client").(func() int32)
+	seen := map[int32]bool{}
+	for i := 0; i < 3; i++ {
+		seen[next()] = true
+	}
+	if len(seen) != 3 || connections != 3 {
+		t.Error("expected round-robin over 3 instances, got: ", seen)
+	}
+}
+
+func TestPoolErrors(t *testing.T) {
+	var closed []string
+	var calls int32
+	loader := Components{
+		"Connections": Annotate(func() (*testCloser, error) {
+			if atomic.AddInt32(&calls, 1) == 2 {
+				return nil, errors.New("connection refused")
+			}
+			return &testCloser{name: "conn", closed: &closed}, nil
+		}, Pool(3)),
+	}.AsLoader()
+	_, err := loader.Load("Connections")
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+	if len(closed) != 2 {
+		t.Error("expected instances loaded successfully to be closed, got: ", closed)
+	}
+
+	_, err = New(Components{
+		"Connections": Annotate(func() int { return 0 }, Pool(0)),
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for empty pool")
+	}
+	_, err = New(Components{
+		"Connections": Annotate(func() error { return nil }, Pool(2)),
+	})
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for pool without result")
+	}
+}
@@ -0,0 +1,56 @@
+package acyclicloader
+
+import "sync"
+
+// A scheduler bounds the number of functions loading components concurrently,
+// letting waiting functions with higher priority run first, see
+// WithMaxConcurrency().
+type scheduler struct {
+	m       sync.Mutex
+	free    int // number of functions that may start without waiting
+	waiting []*scheduled
+	seq     int
+}
+
+// A scheduled is a function waiting for the scheduler.
+type scheduled struct {
+	priority int
+	seq      int           // order of arrival, for functions with equal priority
+	ready    chan struct{} // closed when the function may start
+}
+
+// acquire blocks until a function with priority may start.
+func (s *scheduler) acquire(priority int) {
+	s.m.Lock()
+	if s.free > 0 {
+		s.free--
+		s.m.Unlock()
+		return
+	}
+	s.seq++
+	w := &scheduled{priority: priority, seq: s.seq, ready: make(chan struct{})}
+	s.waiting = append(s.waiting, w)
+	s.m.Unlock()
+	<-w.ready
+}
+
+// release lets the waiting function with highest priority start, or frees a
+// slot if no functions are waiting.
+func (s *scheduler) release() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if len(s.waiting) == 0 {
+		s.free++
+		return
+	}
+	next := 0
+	for i, w := range s.waiting {
+		best := s.waiting[next]
+		if w.priority > best.priority || (w.priority == best.priority && w.seq < best.seq) {
+			next = i
+		}
+	}
+	close(s.waiting[next].ready)
+	s.waiting = append(s.waiting[:next], s.waiting[next+1:]...)
+}
@@ -0,0 +1,58 @@
+package acyclicloader
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriority(t *testing.T) {
+	var m sync.Mutex
+	var order []string
+	record := func(name string) func() string {
+		return func() string {
+			m.Lock()
+			order = append(order, name)
+			m.Unlock()
+			return name
+		}
+	}
+	release := make(chan struct{})
+	loader := Components{
+		"Blocker": func() string {
+			<-release
+			return "blocker"
+		},
+		"Low":    record("Low"),
+		"Medium": Annotate(record("Medium"), Priority(5)),
+		"High":   Annotate(record("High"), Priority(10)),
+	}.AsLoader(WithMaxConcurrency(1))
+
+	// Wait for 'Blocker' to start, and for each of the others to be waiting
+	waitFor := func(ready func(s *scheduler) bool) {
+		for {
+			loader.scheduler.m.Lock()
+			ok := ready(loader.scheduler)
+			loader.scheduler.m.Unlock()
+			if ok {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	loader.goLoad(context.Background(), "Blocker")
+	waitFor(func(s *scheduler) bool { return s.free == 0 })
+	for i, name := range []string{"Low", "Medium", "High"} {
+		loader.goLoad(context.Background(), name)
+		waitFor(func(s *scheduler) bool { return len(s.waiting) > i })
+	}
+	close(release)
+	if err := loader.WaitIdle(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(order) != 3 || order[0] != "High" || order[1] != "Medium" || order[2] != "Low" {
+		t.Error("expected components to load by priority, got: ", order)
+	}
+}
@@ -0,0 +1,90 @@
+package acyclicloader
+
+import "sort"
+
+// profiled holds alternative definitions of a component for different
+// profiles, see Components.ForProfile().
+type profiled struct {
+	fn       interface{} // definition used by other profiles, nil if undefined
+	profiles map[string]interface{}
+}
+
+// ForProfile returns a new set of components holding the components from c,
+// with the definitions from components used only when the loader is created
+// for the given profile, c is not modified.
+//
+// This allows a single set of components to define alternative constructors
+// for different environments, rather than maintaining parallel sets. A
+// component defined only for some profiles is undefined in other profiles.
+//
+//   components := acyclicloader.Components{
+//       "Mailer": func() Mailer { return NewSMTPMailer() },
+//   }.ForProfile("test", acyclicloader.Components{
+//       "Mailer": func() Mailer { return &FakeMailer{} },
+//   })
+//   loader := components.AsLoaderWithProfile("test")
+func (c Components) ForProfile(profile string, components Components) Components {
+	result := make(Components, len(c)+len(components))
+	for name, fn := range c {
+		result[name] = fn
+	}
+	for name, fn := range components {
+		p := &profiled{profiles: map[string]interface{}{}}
+		if existing, ok := result[name].(*profiled); ok {
+			p.fn = existing.fn
+			for profile, fn := range existing.profiles {
+				p.profiles[profile] = fn
+			}
+		} else {
+			p.fn = result[name]
+		}
+		p.profiles[profile] = fn
+		result[name] = p
+	}
+	return result
+}
+
+// AsLoaderWithProfile returns an AcyclicLoader using definitions for the given
+// profile or panics, see ForProfile().
+func (c Components) AsLoaderWithProfile(profile string, options ...Option) *AcyclicLoader {
+	return c.AsLoader(append([]Option{WithProfile(profile)}, options...)...)
+}
+
+// selectProfile returns the definition for profile, or nil if the component
+// isn't defined for profile.
+func (p *profiled) selectProfile(profile string) interface{} {
+	if fn, ok := p.profiles[profile]; ok {
+		return fn
+	}
+	return p.fn
+}
+
+// variants returns all definitions, starting with the default definition if
+// any, followed by definitions for each profile sorted by profile name.
+func (p *profiled) variants() []interface{} {
+	var variants []interface{}
+	if p.fn != nil {
+		variants = append(variants, p.fn)
+	}
+	profiles := make([]string, 0, len(p.profiles))
+	for profile := range p.profiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	for _, profile := range profiles {
+		variants = append(variants, p.profiles[profile])
+	}
+	return variants
+}
+
+// mapVariants returns a profiled component with f applied to all definitions.
+func (p *profiled) mapVariants(f func(fn interface{}) interface{}) *profiled {
+	p2 := &profiled{profiles: make(map[string]interface{}, len(p.profiles))}
+	if p.fn != nil {
+		p2.fn = f(p.fn)
+	}
+	for profile, fn := range p.profiles {
+		p2.profiles[profile] = f(fn)
+	}
+	return p2
+}
@@ -0,0 +1,51 @@
+package acyclicloader
+
+import "testing"
+
+func TestForProfile(t *testing.T) {
+	components := Components{
+		"Host": func() string { return "example.com" },
+		"Port": func() int { return 443 },
+		"Address": func(options struct {
+			Host string
+			Port int
+		}) string {
+			return options.Host
+		},
+	}.ForProfile("test", Components{
+		"Host":  func() string { return "localhost" },
+		"Debug": func() bool { return true },
+	}).ForProfile("staging", Components{
+		"Host": func() string { return "staging.example.com" },
+	})
+
+	if v := components.AsLoader().MustLoad("Address").(string); v != "example.com" {
+		t.Error("expected 'example.com', got: ", v)
+	}
+	if v := components.AsLoaderWithProfile("staging").MustLoad("Address").(string); v != "staging.example.com" {
+		t.Error("expected 'staging.example.com', got: ", v)
+	}
+
+	loader := components.AsLoaderWithProfile("test")
+	if v := loader.MustLoad("Address").(string); v != "localhost" {
+		t.Error("expected 'localhost', got: ", v)
+	}
+	if !loader.MustLoad("Debug").(bool) {
+		t.Error("expected 'Debug' to be defined in the test profile")
+	}
+
+	_, err := components.AsLoader().Load("Debug")
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected 'Debug' to be undefined without profile")
+	}
+
+	// Profiles are preserved when mounting
+	mounted, err := Components{}.Mount("sub", components)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if v := mounted.AsLoaderWithProfile("test").MustLoad("sub/Address").(string); v != "localhost" {
+		t.Error("expected 'localhost', got: ", v)
+	}
+}
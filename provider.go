@@ -0,0 +1,48 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Provide returns a set of components with a component for each exported method
+// of provider, named after the method. The methods are definitions as described
+// in Components, bound to provider, which allows related components to share
+// configuration and unexported helpers.
+//
+//   type Storage struct{ Region string }
+//
+//   func (s *Storage) S3(options struct{ Credentials *aws.Config }) *s3.Client {
+//       return s3.New(s.config(options.Credentials))
+//   }
+//
+//   func (s *Storage) SQS(options struct{ Credentials *aws.Config }) *sqs.Client {
+//       return sqs.New(s.config(options.Credentials))
+//   }
+//
+//   storage, err := acyclicloader.Provide(&Storage{Region: "us-east-1"})
+//   components, err = components.Merge(storage)
+//
+// The provider must be a struct or a pointer to a struct, methods with pointer
+// receivers are only included if provider is a pointer.
+func Provide(provider interface{}) (Components, error) {
+	v := reflect.ValueOf(provider)
+	t := reflect.TypeOf(provider)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, &ComponentDefinitionError{
+			Component: fmt.Sprintf("%T", provider),
+			message: fmt.Sprintf(
+				"expected provider to be a struct or non-nil pointer to a struct, but found %T", provider,
+			),
+		}
+	}
+
+	components := make(Components, v.NumMethod())
+	for i := 0; i < v.NumMethod(); i++ {
+		components[v.Type().Method(i).Name] = v.Method(i).Interface()
+	}
+	return components, nil
+}
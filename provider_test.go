@@ -0,0 +1,71 @@
+package acyclicloader
+
+import (
+	"strings"
+	"testing"
+)
+
+type testStorage struct {
+	region string
+}
+
+func (s *testStorage) endpoint(service string) string {
+	return service + "." + s.region
+}
+
+func (s *testStorage) S3(options struct{ Credentials string }) string {
+	return options.Credentials + "@" + s.endpoint("s3")
+}
+
+func (s *testStorage) SQS(options struct{ Credentials string }) string {
+	return options.Credentials + "@" + s.endpoint("sqs")
+}
+
+func (s testStorage) Region() string {
+	return s.region
+}
+
+func TestProvide(t *testing.T) {
+	storage, err := Provide(&testStorage{region: "eu-west-1"})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if names := strings.Join(storage.Names(), ", "); names != "Region, S3, SQS" {
+		t.Error("unexpected components: ", names)
+	}
+	components, err := Components{
+		"Credentials": "admin",
+	}.Merge(storage)
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	loader := components.AsLoader()
+	if loader.MustLoad("S3").(string) != "admin@s3.eu-west-1" {
+		t.Error("expected 'admin@s3.eu-west-1'")
+	}
+	if loader.MustLoad("SQS").(string) != "admin@sqs.eu-west-1" {
+		t.Error("expected 'admin@sqs.eu-west-1'")
+	}
+
+	// Methods with pointer receivers are not included for values
+	storage, err = Provide(testStorage{region: "eu-west-1"})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if names := strings.Join(storage.Names(), ", "); names != "Region" {
+		t.Error("unexpected components: ", names)
+	}
+
+	_, err = Provide(42)
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error for a provider that isn't a struct")
+	}
+	if _, err := Provide(nil); err == nil {
+		t.Error("expected an error for nil")
+	}
+	var nilStorage *testStorage
+	if _, err := Provide(nilStorage); err == nil {
+		t.Error("expected an error for a nil provider")
+	}
+}
@@ -0,0 +1,101 @@
+package acyclicloader
+
+import (
+	"sync"
+	"time"
+)
+
+// A Recorder records the components loaded by an AcyclicLoader, see
+// AcyclicLoader.Recorder().
+type Recorder struct {
+	m     sync.Mutex
+	loads []RecordedLoad
+}
+
+// A RecordedLoad is a component loaded while a Recorder was recording.
+type RecordedLoad struct {
+	Component string
+	// Components loaded before this component, as it depends on them, lazy
+	// dependencies are not included
+	Dependencies []string
+	Start        time.Time
+	// Duration of the function loading the component, excluding the time spent
+	// loading its dependencies
+	Duration time.Duration
+	Err      error
+}
+
+// Recorder returns a Recorder that records components loaded by a from now on.
+// Cached components are not recorded, as they are not loaded again.
+//
+// Recording components loaded in a test is useful for asserting that a code
+// path doesn't load heavy components:
+//
+//   clone := loader.Clone()
+//   recorder := clone.Recorder()
+//   clone.MustLoad("HealthHandler")
+//   if recorder.Loaded("TemplateEngine") {
+//       t.Error("expected health handler not to load templates")
+//   }
+//
+// Recorders are not inherited by clones of a, so recording a clone won't
+// affect other tests.
+func (a *AcyclicLoader) Recorder() *Recorder {
+	r := &Recorder{}
+	a.m.Lock()
+	a.recorders = append(a.recorders[:len(a.recorders):len(a.recorders)], r)
+	a.m.Unlock()
+	return r
+}
+
+func (r *Recorder) record(component string, dependencies []string, start time.Time, duration time.Duration, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.loads = append(r.loads, RecordedLoad{
+		Component:    component,
+		Dependencies: dependencies,
+		Start:        start,
+		Duration:     duration,
+		Err:          err,
+	})
+}
+
+// Loads returns the components loaded in the order they finished loading,
+// which is such that dependencies come before dependents. Transient components
+// are recorded each time they are loaded.
+func (r *Recorder) Loads() []RecordedLoad {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return append([]RecordedLoad(nil), r.loads...)
+}
+
+// Components returns the names of the components loaded, in the order they
+// finished loading.
+func (r *Recorder) Components() []string {
+	r.m.Lock()
+	defer r.m.Unlock()
+	names := make([]string, len(r.loads))
+	for i, load := range r.loads {
+		names[i] = load.Component
+	}
+	return names
+}
+
+// Loaded returns true, if component was loaded while recording.
+func (r *Recorder) Loaded(component string) bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+	for _, load := range r.loads {
+		if load.Component == component {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset clears the components recorded so far.
+func (r *Recorder) Reset() {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.loads = nil
+}
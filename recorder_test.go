@@ -0,0 +1,52 @@
+package acyclicloader
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	loader := Components{
+		"Config": func() string { return "config" },
+		"Templates": func() string {
+			time.Sleep(10 * time.Millisecond)
+			return "templates"
+		},
+		"Handler": func(options struct{ Config string }) string { return "handler" },
+		"Broken":  func() (string, error) { return "", errors.New("broken") },
+	}.AsLoader()
+	loader.MustLoad("Config")
+
+	recorder := loader.Recorder()
+	loader.MustLoad("Handler")
+	if recorder.Loaded("Templates") || recorder.Loaded("Config") {
+		t.Error("expected only 'Handler' to be recorded, got: ", recorder.Components())
+	}
+	loader.MustLoad("Templates")
+	loader.Load("Broken")
+
+	components := recorder.Components()
+	if len(components) != 3 || components[0] != "Handler" || components[1] != "Templates" {
+		t.Fatal("unexpected components recorded: ", components)
+	}
+	loads := recorder.Loads()
+	if loads[1].Duration < 10*time.Millisecond {
+		t.Error("expected duration of 'Templates' to be recorded, got: ", loads[1].Duration)
+	}
+	if loads[2].Err == nil {
+		t.Error("expected error from 'Broken' to be recorded")
+	}
+
+	clone := loader.Clone()
+	clone.Shutdown()
+	clone.MustLoad("Config")
+	if recorder.Loaded("Config") {
+		t.Error("expected clone not to be recorded")
+	}
+
+	recorder.Reset()
+	if len(recorder.Loads()) != 0 {
+		t.Error("expected no loads after Reset()")
+	}
+}
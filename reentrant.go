@@ -0,0 +1,127 @@
+package acyclicloader
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// A goroutineLoads tracks the components a goroutine is calling the functions
+// loading, and the serialize locks it holds while doing so, such that these
+// functions may call Load() without deadlocking.
+type goroutineLoads struct {
+	components []string
+	locks      map[*sync.Mutex]bool
+}
+
+// enterConstructor records that the current goroutine is calling the function
+// loading component, and returns the locks from serialize that it must acquire,
+// along with a function to call when the function returns. Both must be called
+// with a.m locked.
+func (a *AcyclicLoader) enterConstructor(component string, serialize []*sync.Mutex) (acquire []*sync.Mutex, leave func()) {
+	id := goroutineID()
+	if a.constructing == nil {
+		a.constructing = map[int64]*goroutineLoads{}
+	}
+	g := a.constructing[id]
+	if g == nil {
+		g = &goroutineLoads{locks: map[*sync.Mutex]bool{}}
+		a.constructing[id] = g
+	}
+	g.components = append(g.components, component)
+	for _, m := range serialize {
+		if !g.locks[m] {
+			g.locks[m] = true
+			acquire = append(acquire, m)
+		}
+	}
+	return acquire, func() {
+		for _, m := range acquire {
+			delete(g.locks, m)
+		}
+		g.components = g.components[:len(g.components)-1]
+		if len(g.components) == 0 {
+			delete(a.constructing, id)
+		}
+	}
+}
+
+// holdsSerializeLock returns true, if the current goroutine holds any of the
+// serialize locks needed for loading c, such that waiting for another goroutine
+// loading c would deadlock. This must be called with a.m locked.
+func (a *AcyclicLoader) holdsSerializeLock(c *component) bool {
+	if len(a.constructing) == 0 {
+		return false
+	}
+	g := a.constructing[goroutineID()]
+	if g == nil {
+		return false
+	}
+	for _, m := range a.serializeLocks(c) {
+		if g.locks[m] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReentrant returns a ReentrantLoadError, if loading component would
+// deadlock because it depends on a component, which the current goroutine is
+// in the middle of loading. This must be called with a.m locked.
+func (a *AcyclicLoader) checkReentrant(component string) error {
+	if len(a.constructing) == 0 {
+		return nil
+	}
+	g := a.constructing[goroutineID()]
+	if g == nil {
+		return nil
+	}
+	for i, name := range g.components {
+		if path := a.dependencyPath(component, name); path != nil {
+			chain := append(append([]string(nil), g.components[i:]...), path...)
+			return &ReentrantLoadError{Chain: chain}
+		}
+	}
+	return nil
+}
+
+// dependencyPath returns the chain of non-lazy dependencies from component to
+// target, or nil if component doesn't depend on target. Must be called with
+// a.m locked.
+func (a *AcyclicLoader) dependencyPath(component, target string) []string {
+	visited := map[string]bool{}
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		if name == target {
+			return []string{name}
+		}
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+		c := a.components[name]
+		for i, dep := range c.dependencies {
+			if c.lazy[i] {
+				continue // lazy dependencies don't block loading
+			}
+			if path := visit(dep); path != nil {
+				return append([]string{name}, path...)
+			}
+		}
+		return nil
+	}
+	return visit(component)
+}
+
+// goroutineID returns the id of the current goroutine, parsed from the header
+// of its stack trace, as the runtime doesn't expose it otherwise.
+func goroutineID() int64 {
+	var buf [64]byte
+	header := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(header, ' '); i >= 0 {
+		header = header[:i]
+	}
+	id, _ := strconv.ParseInt(string(header), 10, 64)
+	return id
+}
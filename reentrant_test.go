@@ -0,0 +1,78 @@
+package acyclicloader
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReentrantLoad(t *testing.T) {
+	var loader *AcyclicLoader
+	var reentrantErr error
+	loader = Components{
+		"Port": func() int { return 80 },
+		"Server": func(options struct{ Port int }) int {
+			// Loading a component that doesn't depend on 'Server' is fine
+			if loader.MustLoad("Port").(int) != options.Port {
+				t.Error("expected the cached port")
+			}
+			_, reentrantErr = loader.Load("Handler")
+			return options.Port
+		},
+		"Routes":  func(options struct{ Server int }) int { return options.Server },
+		"Handler": func(options struct{ Routes int }) int { return options.Routes },
+	}.AsLoader()
+
+	if v, err := loader.Load("Server"); err != nil || v != 80 {
+		t.Fatal("expected 'Server' to load, got: ", err)
+	}
+	t.Logf("got error as expected: '%s'", reentrantErr)
+	e, ok := reentrantErr.(*ReentrantLoadError)
+	if !ok {
+		t.Fatal("expected a ReentrantLoadError")
+	}
+	if strings.Join(e.Chain, " -> ") != "Server -> Handler -> Routes -> Server" {
+		t.Error("unexpected chain: ", e.Chain)
+	}
+	if _, err := loader.Load("Handler"); err != nil {
+		t.Error("expected 'Handler' to load once 'Server' is loaded, got: ", err)
+	}
+}
+
+func TestReentrantLoadPlugins(t *testing.T) {
+	var loader *AcyclicLoader
+	loader = Components{
+		"Connection":  Annotate(func() int { return 1 }, SerializeDependents()),
+		"PluginNames": func() []string { return []string{"A", "B"} },
+		"A":           func(options struct{ Connection int }) string { return "a" },
+		"B":           func(options struct{ Connection int }) string { return "b" },
+		"Plugins": func(options struct {
+			Connection  int
+			PluginNames []string
+		}) (string, error) {
+			// Plugins share 'Connection', whose lock is held by this goroutine
+			var plugins []string
+			for _, name := range options.PluginNames {
+				plugin, err := loader.Load(name)
+				if err != nil {
+					return "", err
+				}
+				plugins = append(plugins, plugin.(string))
+			}
+			return strings.Join(plugins, ","), nil
+		},
+	}.AsLoader()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if v, err := loader.Load("Plugins"); err != nil || v != "a,b" {
+			t.Error("expected 'a,b', got: ", v, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("loading plugins deadlocked")
+	}
+}
@@ -0,0 +1,78 @@
+package acyclicloader
+
+import "context"
+
+// Refresh closes the named component and all loaded components depending on
+// it, as in Shutdown(), and loads them again. This is useful for rotating
+// credentials or reconnecting clients without restarting the process.
+//
+// Overwritten values and components inherited from a parent loader are not
+// refreshed. Components holding a lazy getter for the component aren't
+// refreshed either, they obtain the new value the next time the getter is
+// called, unless the getter is for a transient component depending on it.
+//
+// If a component fails to load again, the error from loading it is returned,
+// otherwise any error from closing old values is returned.
+func (a *AcyclicLoader) Refresh(name string) error {
+	a.m.Lock()
+	_, ok := a.components[name]
+	a.m.Unlock()
+	if !ok {
+		_, err := a.Load(name) // returns UndefinedComponentError
+		return err
+	}
+
+	purged, closeErr := a.shutdown(func(n string) bool { return n == name })
+	if len(purged) == 0 {
+		purged = []string{name}
+	}
+
+	// Keyed components are loaded again by the next LoadKeyed()
+	a.m.Lock()
+	var names []string
+	for _, n := range purged {
+		if !a.components[n].keyed {
+			names = append(names, n)
+		}
+	}
+	purged = names
+	a.m.Unlock()
+
+	for _, n := range purged {
+		a.goLoad(context.Background(), n)
+	}
+	var err error
+	for _, n := range purged {
+		if _, loadErr := a.Load(n); err == nil {
+			err = loadErr
+		}
+	}
+	a.m.Lock()
+	for _, n := range purged {
+		a.emit(Refreshed, n, 0, a.components[n].err)
+	}
+	a.m.Unlock()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// Invalidate drops the cached value/err pair of the named component, and of all
+// loaded components depending on it, without loading them again or closing old
+// values. The components are loaded again by the next Load() needing them.
+//
+// Overwritten values and components inherited from a parent loader are not
+// invalidated. An UndefinedComponentError is returned, if the component isn't
+// defined.
+func (a *AcyclicLoader) Invalidate(name string) error {
+	a.m.Lock()
+	_, ok := a.components[name]
+	a.m.Unlock()
+	if !ok {
+		_, err := a.Load(name) // returns UndefinedComponentError
+		return err
+	}
+	a.purge(func(n string) bool { return n == name })
+	return nil
+}
@@ -0,0 +1,188 @@
+package acyclicloader
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefresh(t *testing.T) {
+	var closed []string
+	credentials := 0
+	loader := Components{
+		"Credentials": func() *testCloser {
+			credentials++
+			return &testCloser{name: "Credentials", closed: &closed}
+		},
+		"Client": func(options struct{ Credentials *testCloser }) *testCloser {
+			return &testCloser{name: "Client", closed: &closed}
+		},
+		"Unrelated": func() *testCloser {
+			return &testCloser{name: "Unrelated", closed: &closed}
+		},
+	}.AsLoader()
+	client := loader.MustLoad("Client")
+	loader.MustLoad("Unrelated")
+
+	if err := loader.Refresh("Credentials"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(closed) != 2 || closed[0] != "Client" || closed[1] != "Credentials" {
+		t.Error("expected 'Client' and 'Credentials' to be closed, got: ", closed)
+	}
+	if credentials != 2 {
+		t.Error("expected 'Credentials' to be loaded again")
+	}
+	if loader.MustLoad("Client") == client {
+		t.Error("expected 'Client' to be loaded again")
+	}
+
+	err := loader.Refresh("Credentials")
+	if err != nil || credentials != 3 {
+		t.Error("expected 'Credentials' to be loaded again, got: ", err)
+	}
+
+	err = loader.Refresh("Credentails")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*UndefinedComponentError); !ok {
+		t.Error("expected an UndefinedComponentError")
+	}
+}
+
+func TestRefreshThroughTransient(t *testing.T) {
+	var closed []string
+	credentials := 0
+	loader := Components{
+		"Credentials": func() *testCloser {
+			credentials++
+			return &testCloser{name: "Credentials", closed: &closed}
+		},
+		"Session": Annotate(func(options struct{ Credentials *testCloser }) *testCloser {
+			return options.Credentials
+		}, Transient()),
+		"Client": func(options struct{ Session *testCloser }) *testCloser {
+			return options.Session
+		},
+		"Worker": func(options struct {
+			Session func() (*testCloser, error) `component:",lazy"`
+		}) *testCloser {
+			session, _ := options.Session()
+			return session
+		},
+	}.AsLoader()
+	client := loader.MustLoad("Client")
+	worker := loader.MustLoad("Worker")
+
+	if err := loader.Refresh("Credentials"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if credentials != 2 {
+		t.Error("expected 'Credentials' to be loaded again")
+	}
+	if loader.MustLoad("Client") == client {
+		t.Error("expected 'Client' to be loaded again, as 'Session' is transient")
+	}
+	if loader.MustLoad("Worker") == worker {
+		t.Error("expected 'Worker' to be loaded again, as it lazily depends on 'Session'")
+	}
+}
+
+func TestRefreshLazy(t *testing.T) {
+	var closed []string
+	credentials, clients := 0, 0
+	loader := Components{
+		"Credentials": func() *testCloser {
+			credentials++
+			return &testCloser{name: "Credentials", closed: &closed}
+		},
+		"Client": func(options struct {
+			Credentials func() (*testCloser, error) `component:",lazy"`
+		}) func() (*testCloser, error) {
+			clients++
+			return options.Credentials
+		},
+	}.AsLoader()
+	client := loader.MustLoad("Client").(func() (*testCloser, error))
+	old, _ := client()
+
+	if err := loader.Refresh("Credentials"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(closed) != 1 || closed[0] != "Credentials" {
+		t.Error("expected only 'Credentials' to be closed, got: ", closed)
+	}
+	loader.MustLoad("Client")
+	if clients != 1 {
+		t.Error("expected 'Client' not to be loaded again, as it lazily depends on 'Credentials'")
+	}
+	if current, _ := client(); current == old || credentials != 2 {
+		t.Error("expected the getter to return the new value")
+	}
+}
+
+func TestRefreshLoadsOnce(t *testing.T) {
+	var credentials, clients int32
+	loader := Components{
+		"Credentials": func() int {
+			time.Sleep(time.Millisecond) // give other goroutines a chance to load it
+			return int(atomic.AddInt32(&credentials, 1))
+		},
+		"Client": func(options struct{ Credentials int }) int {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&clients, 1)
+			return options.Credentials
+		},
+	}.AsLoader()
+	loader.MustLoad("Client")
+
+	for i := 2; i < 20; i++ {
+		if err := loader.Refresh("Credentials"); err != nil {
+			t.Fatal("unexpected error: ", err)
+		}
+		if atomic.LoadInt32(&credentials) != int32(i) || atomic.LoadInt32(&clients) != int32(i) {
+			t.Fatal("expected each component to be loaded once per refresh, got: ",
+				atomic.LoadInt32(&credentials), atomic.LoadInt32(&clients))
+		}
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	var closed []string
+	loads := map[string]int{}
+	loader := Components{
+		"Credentials": func() *testCloser {
+			loads["Credentials"]++
+			return &testCloser{name: "Credentials", closed: &closed}
+		},
+		"Client": func(options struct{ Credentials *testCloser }) int {
+			loads["Client"]++
+			return loads["Credentials"]
+		},
+		"Unrelated": func() int {
+			loads["Unrelated"]++
+			return 0
+		},
+	}.AsLoader()
+	loader.MustLoad("Client")
+	loader.MustLoad("Unrelated")
+
+	if err := loader.Invalidate("Credentials"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(closed) != 0 || loads["Credentials"] != 1 {
+		t.Error("expected no values to be closed or loaded")
+	}
+	if loader.MustLoad("Client").(int) != 2 || loads["Client"] != 2 {
+		t.Error("expected 'Client' to be loaded again with new 'Credentials'")
+	}
+	loader.MustLoad("Unrelated")
+	if loads["Unrelated"] != 1 {
+		t.Error("expected 'Unrelated' to stay cached")
+	}
+
+	err := loader.Invalidate("Clinet")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*UndefinedComponentError); !ok {
+		t.Error("expected an UndefinedComponentError")
+	}
+}
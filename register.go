@@ -0,0 +1,59 @@
+package acyclicloader
+
+import "fmt"
+
+// Register adds a component to the AcyclicLoader after it has been created,
+// this is useful for plugins extending an application.
+//
+// The component is validated the same way as in New(). As components already
+// defined cannot depend on a component that wasn't defined, registering a
+// component cannot introduce a dependency cycle, unless the component depends
+// on itself. Clones and loaders created with WithOverwrites() before Register()
+// is called will not have the component.
+func (a *AcyclicLoader) Register(name string, fn interface{}) error {
+	c, err := newComponent(name, fn)
+	if err != nil {
+		return err
+	}
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if err := a.checkFrozen("Register"); err != nil {
+		return err
+	}
+	if _, ok := a.components[name]; ok {
+		return &ComponentDefinitionError{
+			Component: name,
+			message:   fmt.Sprintf("cannot register '%s' as it is already defined", name),
+		}
+	}
+
+	a.components[name] = c
+	err = a.resolveDependencies(name, sortedKeys(a.components))
+	if err == nil && stringContains(c.dependencies, name) {
+		err = &ComponentDefinitionError{
+			Component: name,
+			message:   fmt.Sprintf("dependency cycle detected: '%s' -> '%s'", name, name),
+		}
+	}
+	for _, dep := range c.dependencies {
+		if err == nil {
+			err = a.warn(a.checkGoroutineSafety(dep))
+		}
+	}
+	if err != nil {
+		delete(a.components, name)
+		return err
+	}
+
+	// Definitions may be shared with clones, so we copy before modifying
+	definitions := make(Components, len(a.definitions)+1)
+	for n, fn := range a.definitions {
+		definitions[n] = fn
+	}
+	definitions[name] = fn
+	a.definitions = definitions
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package acyclicloader
+
+import "testing"
+
+func TestRegister(t *testing.T) {
+	loader, _ := New(Components{
+		"A": func() int { return 5 },
+	})
+
+	err := loader.Register("B", func(options struct{ A int }) int {
+		return options.A + 2
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if loader.MustLoad("B").(int) != 7 {
+		t.Error("expected 7")
+	}
+
+	// Registered components are kept when overwriting functions
+	replaced, err := loader.WithOverwriteFuncs(map[string]interface{}{
+		"A": func() int { return 1 },
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if replaced.MustLoad("B").(int) != 3 {
+		t.Error("expected 3")
+	}
+
+	for name, fn := range map[string]interface{}{
+		"A": func() int { return 1 },
+		"C": func(options struct{ C int }) int { return options.C },
+		"D": func(options struct{ A string }) string { return options.A },
+		"E": func(options struct{ Undefined int }) int { return options.Undefined },
+	} {
+		err := loader.Register(name, fn)
+		t.Logf("got error as expected: '%s'", err)
+		if err == nil {
+			t.Errorf("expected an error registering '%s'", name)
+		}
+	}
+	if _, err := loader.Load("C"); err == nil {
+		t.Error("expected 'C' not to be registered")
+	}
+}
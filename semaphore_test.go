@@ -0,0 +1,46 @@
+package acyclicloader
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimitedBy(t *testing.T) {
+	var running, maxRunning int32
+	call := func() int {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return 1
+	}
+	loader := Components{
+		"A": Annotate(call, LimitedBy("external-api")),
+		"B": Annotate(call, LimitedBy("external-api")),
+		"C": Annotate(call, LimitedBy("external-api")),
+		"D": Annotate(call, LimitedBy("external-api")),
+		"Root": func(options struct{ A, B, C, D int }) int {
+			return options.A + options.B + options.C + options.D
+		},
+	}.AsLoader(WithSemaphore("external-api", 2))
+
+	if loader.MustLoad("Root").(int) != 4 {
+		t.Error("expected 4")
+	}
+	if maxRunning > 2 {
+		t.Error("expected at most 2 functions to run concurrently, got: ", maxRunning)
+	}
+
+	_, err := New(Components{
+		"A": Annotate(call, LimitedBy("undefined")),
+	})
+	if _, ok := err.(*ComponentDefinitionError); !ok {
+		t.Error("expected a ComponentDefinitionError for an undefined semaphore, got: ", err)
+	}
+}
@@ -0,0 +1,154 @@
+package acyclicloader
+
+import "io"
+
+// Shutdown closes all loaded components implementing io.Closer, components are
+// closed before the components they depend on. All loaded components are purged
+// from the cache, such that loading them again creates new values.
+//
+// Components inherited from a parent loader and overwritten values are not
+// closed. Values shared with clones of the loader are closed too, and Shutdown
+// should not be called while components are being loaded.
+//
+// A ShutdownError is returned, if any component failed to close.
+func (a *AcyclicLoader) Shutdown() error {
+	a.m.Lock()
+	a.emit(ShutdownStarted, "", 0, nil)
+	a.m.Unlock()
+	_, err := a.shutdown(func(string) bool { return true })
+	return err
+}
+
+// Reset closes loaded components implementing io.Closer as in Shutdown(), and
+// clears all state from loading components, returning the loader to the state
+// it was created in. This includes failures counted by CircuitBreaker(), and
+// copies of values inherited from a parent loader. This is intended for
+// long-lived test processes and tools that keep using the loader afterwards.
+//
+// Values given to WithOverwrites() are kept, as they are part of the state the
+// loader was created in. Unlike Shutdown(), Reset doesn't emit a
+// ShutdownStarted event, nor any other event.
+func (a *AcyclicLoader) Reset() error {
+	_, err := a.shutdown(func(string) bool { return true })
+
+	a.m.Lock()
+	defer a.m.Unlock()
+	for _, c := range a.components {
+		if !c.overwritten {
+			c.reset()
+		}
+	}
+	a.generation++
+	return err
+}
+
+// shutdown closes all loaded components for which match returns true, along
+// with loaded components depending on them, as they would otherwise hold on to
+// closed values. This returns the components purged, such that dependencies
+// come before dependents.
+func (a *AcyclicLoader) shutdown(match func(name string) bool) ([]string, error) {
+	order, values := a.purge(match)
+
+	errs := map[string]error{}
+	for i := len(order) - 1; i >= 0; i-- {
+		for _, value := range values[i] {
+			if closer, ok := value.(io.Closer); ok {
+				if err := closer.Close(); err != nil && errs[order[i]] == nil {
+					errs[order[i]] = err
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return order, &ShutdownError{Errors: errs}
+	}
+	return order, nil
+}
+
+// purge removes the value/err pairs of all loaded components for which match
+// returns true, along with loaded components depending on them, except those
+// holding a lazy getter for a component that isn't transient. This returns
+// the components purged and their values, such that dependencies come before
+// dependents. Keyed components have a value for each loaded instance.
+func (a *AcyclicLoader) purge(match func(name string) bool) ([]string, [][]interface{}) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	return a.purgeLocked(match, false)
+}
+
+// purgeLocked purges components as in purge(), if inherited is true matching
+// components inherited from a parent loader are purged too, this must be
+// called with a.m locked.
+func (a *AcyclicLoader) purgeLocked(match func(name string) bool, inherited bool) ([]string, [][]interface{}) {
+	purging := make(map[string]bool, len(a.components))
+	var mustPurge func(name string) bool
+	mustPurge = func(name string) bool {
+		if v, ok := purging[name]; ok {
+			return v
+		}
+		c := a.components[name]
+		purging[name] = false
+		// Transient components are never cached, but dependents hold values
+		// built from their dependencies, so they are purged through them
+		loaded := c.loaded || len(c.instances) > 0 || c.transient
+		if !loaded || (c.inherited && !inherited) || c.overwritten {
+			return false
+		}
+		if match(name) {
+			purging[name] = true
+		}
+		for i, dep := range c.dependencies {
+			// Lazy getters load dependencies again when called, but values of
+			// transient dependencies obtained from them may be held
+			if c.lazy[i] && !a.components[dep].transient {
+				continue
+			}
+			if mustPurge(dep) {
+				purging[name] = true
+			}
+		}
+		return purging[name]
+	}
+
+	// Order components such that dependencies come before dependents
+	var order []string
+	visited := make(map[string]bool, len(a.components))
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, dep := range a.components[name].dependencies {
+			visit(dep)
+		}
+		if mustPurge(name) && !a.components[name].transient {
+			order = append(order, name)
+		}
+	}
+	for _, name := range sortedKeys(a.components) {
+		visit(name)
+	}
+
+	// Purge components from the cache
+	if len(order) > 0 {
+		a.generation++
+	}
+	values := make([][]interface{}, len(order))
+	for i, name := range order {
+		c := a.components[name]
+		if c.keyed {
+			for _, key := range sortedKeys(c.instances) {
+				values[i] = append(values[i], c.instances[key].value)
+			}
+			c.instances = nil
+			continue
+		}
+		values[i] = []interface{}{c.value}
+		c.value = nil
+		c.err = nil
+		c.loaded = false
+		c.loading = false
+	}
+	return order, values
+}
@@ -0,0 +1,137 @@
+package acyclicloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testCloser struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (c *testCloser) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+func TestShutdown(t *testing.T) {
+	var closed []string
+	loader := Components{
+		"Database": func() *testCloser {
+			return &testCloser{name: "Database", closed: &closed}
+		},
+		"Server": func(options struct{ Database *testCloser }) *testCloser {
+			return &testCloser{name: "Server", closed: &closed}
+		},
+		"Port": func() int { return 80 },
+		"Unused": func() *testCloser {
+			return &testCloser{name: "Unused", closed: &closed}
+		},
+	}.AsLoader()
+	server := loader.MustLoad("Server")
+	loader.MustLoad("Port")
+
+	if err := loader.Shutdown(); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(closed) != 2 || closed[0] != "Server" || closed[1] != "Database" {
+		t.Error("expected 'Server' to be closed before 'Database', got: ", closed)
+	}
+	if loader.MustLoad("Server") == server {
+		t.Error("expected 'Server' to be loaded again after shutdown")
+	}
+}
+
+func TestShutdownError(t *testing.T) {
+	var closed []string
+	loader := Components{
+		"Database": func() *testCloser {
+			return &testCloser{name: "Database", closed: &closed, err: errors.New("broken")}
+		},
+	}.AsLoader()
+	loader.MustLoad("Database")
+
+	err := loader.Shutdown()
+	t.Logf("got error as expected: '%s'", err)
+	if e, ok := err.(*ShutdownError); !ok || e.Errors["Database"] == nil {
+		t.Error("expected a ShutdownError for 'Database'")
+	}
+}
+
+func TestReset(t *testing.T) {
+	var closed []string
+	loads := 0
+	loader, err := Components{
+		"Database": func() *testCloser {
+			loads++
+			return &testCloser{name: "Database", closed: &closed}
+		},
+		"Broken": func() (int, error) {
+			loads++
+			return 0, errors.New("broken")
+		},
+		"Port": func() int { return 80 },
+	}.AsLoader().WithOverwrites(map[string]interface{}{"Port": 443})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	loader.MustLoad("Database")
+	loader.Load("Broken")
+
+	if err := loader.Reset(); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(closed) != 1 {
+		t.Error("expected 'Database' to be closed, got: ", closed)
+	}
+	loader.MustLoad("Database")
+	loader.Load("Broken")
+	if loads != 4 {
+		t.Error("expected cached values and errors to be cleared")
+	}
+	if loader.MustLoad("Port").(int) != 443 {
+		t.Error("expected overwritten value to be kept")
+	}
+}
+
+func TestResetCircuitBreaker(t *testing.T) {
+	attempts := 0
+	loader := Components{
+		"Remote": Annotate(func() (int, error) {
+			attempts++
+			return 0, errors.New("connection refused")
+		}, CircuitBreaker(2, time.Hour)),
+	}.AsLoader()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := loader.Events(ctx)
+
+	loader.Load("Remote")
+	loader.Load("Remote")
+	loader.Load("Remote")
+	if attempts != 2 {
+		t.Fatal("expected circuit to open after 2 attempts, got: ", attempts)
+	}
+
+	if err := loader.Reset(); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	loader.Load("Remote")
+	if loader.Loaded("Remote") {
+		t.Error("expected failures counted before Reset to be cleared")
+	}
+	loader.Load("Remote")
+	if attempts != 4 {
+		t.Error("expected Reset to close the circuit, got attempts: ", attempts)
+	}
+
+	for len(events) > 0 {
+		if e := <-events; e.Kind == ShutdownStarted {
+			t.Error("expected Reset not to emit ShutdownStarted")
+		}
+	}
+}
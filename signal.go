@@ -0,0 +1,98 @@
+package acyclicloader
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const defaultGracePeriod = 30 * time.Second
+
+// RunUntilSignal loads the roots given, and blocks until the process receives
+// SIGINT or SIGTERM, then all loaded components are shut down, such that
+// components are shut down before the components they depend on.
+//
+// Components with a method Shutdown(context.Context) error, such as
+// *http.Server, are drained by calling this method with a context that expires
+// after the grace period, see WithGracePeriod(). Other components implementing
+// io.Closer are closed. If the grace period expires, or a second signal is
+// received, remaining components are closed without draining.
+//
+//   func main() {
+//       if err := loader.RunUntilSignal("Server", "Worker"); err != nil {
+//           log.Fatal(err)
+//       }
+//   }
+//
+// If a root fails to load, components loaded are shut down and the error is
+// returned. Otherwise, a ShutdownError is returned if any component failed to
+// shut down.
+func (a *AcyclicLoader) RunUntilSignal(roots ...string) error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	return a.runUntil(signals, roots)
+}
+
+// runUntil loads roots and shuts down when signals receives a value.
+func (a *AcyclicLoader) runUntil(signals <-chan os.Signal, roots []string) error {
+	for _, root := range roots {
+		a.goLoad(context.Background(), root)
+	}
+	for _, root := range roots {
+		if _, err := a.Load(root); err != nil {
+			a.drain(context.Background(), true)
+			return err
+		}
+	}
+
+	<-signals
+	gracePeriod := a.gracePeriod
+	if gracePeriod == 0 {
+		gracePeriod = defaultGracePeriod
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return a.drain(ctx, false)
+}
+
+// drain shuts down all loaded components in reverse order, calling
+// Shutdown(ctx) on components that have this method, unless force is true or
+// ctx is done, and Close() on other components implementing io.Closer.
+func (a *AcyclicLoader) drain(ctx context.Context, force bool) error {
+	order, values := a.purge(func(string) bool { return true })
+
+	errs := map[string]error{}
+	for i := len(order) - 1; i >= 0; i-- {
+		for _, value := range values[i] {
+			var err error
+			if d, ok := value.(interface {
+				Shutdown(context.Context) error
+			}); ok && !force && ctx.Err() == nil {
+				err = d.Shutdown(ctx)
+				if closer, ok := value.(io.Closer); ok && ctx.Err() != nil {
+					closer.Close() // grace period expired while draining
+				}
+			} else if closer, ok := value.(io.Closer); ok {
+				err = closer.Close()
+			}
+			if err != nil && errs[order[i]] == nil {
+				errs[order[i]] = err
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &ShutdownError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package acyclicloader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testDrainer struct {
+	testCloser
+	block bool
+}
+
+func (d *testDrainer) Shutdown(ctx context.Context) error {
+	if d.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	*d.closed = append(*d.closed, "drained "+d.name)
+	return nil
+}
+
+func TestRunUntilSignal(t *testing.T) {
+	var closed []string
+	loader := Components{
+		"Database": func() *testCloser {
+			return &testCloser{name: "Database", closed: &closed}
+		},
+		"Server": func(options struct{ Database *testCloser }) *testDrainer {
+			return &testDrainer{testCloser: testCloser{name: "Server", closed: &closed}}
+		},
+	}.AsLoader()
+
+	signals := make(chan os.Signal, 1)
+	signals <- os.Interrupt
+	if err := loader.runUntil(signals, []string{"Server"}); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(closed) != 2 || closed[0] != "drained Server" || closed[1] != "Database" {
+		t.Error("expected 'Server' to be drained before closing 'Database', got: ", closed)
+	}
+}
+
+func TestRunUntilSignalLoadsOnce(t *testing.T) {
+	var listeners int32
+	loader := Components{
+		"Server": func() *testDrainer {
+			time.Sleep(time.Millisecond) // give other goroutines a chance to load it
+			atomic.AddInt32(&listeners, 1)
+			return &testDrainer{testCloser: testCloser{name: "Server", closed: new([]string)}}
+		},
+	}.AsLoader()
+
+	signals := make(chan os.Signal, 1)
+	signals <- os.Interrupt
+	if err := loader.runUntil(signals, []string{"Server"}); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if n := atomic.LoadInt32(&listeners); n != 1 {
+		t.Error("expected 'Server' to be loaded once, got: ", n)
+	}
+}
+
+func TestRunUntilSignalGracePeriod(t *testing.T) {
+	var closed []string
+	loader := Components{
+		"Server": func() *testDrainer {
+			return &testDrainer{testCloser: testCloser{name: "Server", closed: &closed}, block: true}
+		},
+	}.AsLoader(WithGracePeriod(10 * time.Millisecond))
+
+	signals := make(chan os.Signal, 1)
+	signals <- os.Interrupt
+	err := loader.runUntil(signals, []string{"Server"})
+	var e *ShutdownError
+	if !errors.As(err, &e) || e.Errors["Server"] != context.DeadlineExceeded {
+		t.Error("expected grace period to expire, got: ", err)
+	}
+	if len(closed) != 1 || closed[0] != "Server" {
+		t.Error("expected 'Server' to be closed after grace period, got: ", closed)
+	}
+}
+
+func TestRunUntilSignalLoadError(t *testing.T) {
+	var closed []string
+	loader := Components{
+		"Database": func() *testCloser {
+			return &testCloser{name: "Database", closed: &closed}
+		},
+		"Broken": func() (int, error) { return 0, errors.New("broken") },
+	}.AsLoader()
+
+	err := loader.runUntil(nil, []string{"Database", "Broken"})
+	if err == nil || err.Error() != "broken" {
+		t.Fatal("expected error from 'Broken', got: ", err)
+	}
+	if len(closed) != 1 || closed[0] != "Database" {
+		t.Error("expected 'Database' to be closed, got: ", closed)
+	}
+}
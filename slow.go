@@ -0,0 +1,82 @@
+package acyclicloader
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// watchSlow reports a warning if component is still loading after the
+// threshold given to WithSlowThreshold(), and again each time the time spent
+// loading doubles, until the returned function is called.
+func (a *AcyclicLoader) watchSlow(component string, start time.Time) (stop func()) {
+	if a.slow <= 0 {
+		return func() {}
+	}
+	var m sync.Mutex
+	var timer *time.Timer
+	stopped := false
+	var warn func()
+	warn = func() {
+		elapsed := time.Since(start)
+		a.m.Lock()
+		blocked := a.blockedBy(component)
+		if len(blocked) > 0 {
+			a.logger.Printf(
+				"warning: component '%s' is still loading after %s, blocking '%s'",
+				component, elapsed.Round(time.Millisecond), strings.Join(blocked, "', '"),
+			)
+		} else {
+			a.logger.Printf(
+				"warning: component '%s' is still loading after %s",
+				component, elapsed.Round(time.Millisecond),
+			)
+		}
+		a.emit(LoadSlow, component, elapsed, nil)
+		a.m.Unlock()
+
+		m.Lock()
+		defer m.Unlock()
+		if !stopped {
+			timer = time.AfterFunc(elapsed, warn) // warn again when elapsed doubles
+		}
+	}
+	m.Lock()
+	timer = time.AfterFunc(a.slow, warn)
+	m.Unlock()
+	return func() {
+		m.Lock()
+		defer m.Unlock()
+		stopped = true
+		timer.Stop()
+	}
+}
+
+// blockedBy returns a sorted list of components waiting for component to be
+// loaded, directly or through other dependencies, this must be called with a.m
+// locked. Lazy dependencies are ignored, as they don't block loading.
+func (a *AcyclicLoader) blockedBy(component string) []string {
+	depends := map[string]bool{component: true}
+	var dependsOn func(name string) bool
+	dependsOn = func(name string) bool {
+		if v, ok := depends[name]; ok {
+			return v
+		}
+		depends[name] = false
+		c := a.components[name]
+		for i, dep := range c.dependencies {
+			if !c.lazy[i] && dependsOn(dep) {
+				depends[name] = true
+			}
+		}
+		return depends[name]
+	}
+
+	var blocked []string
+	for _, name := range sortedKeys(a.components) {
+		if name != component && a.components[name].queued > 0 && dependsOn(name) {
+			blocked = append(blocked, name)
+		}
+	}
+	return blocked
+}
@@ -0,0 +1,65 @@
+package acyclicloader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncLogger struct {
+	m        sync.Mutex
+	messages []string
+}
+
+func (l *syncLogger) Printf(format string, v ...interface{}) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestSlowThreshold(t *testing.T) {
+	release := make(chan struct{})
+	logger := &syncLogger{}
+	loader := Components{
+		"Database": func() string {
+			<-release
+			return "database"
+		},
+		"Users":  func(options struct{ Database string }) string { return "users" },
+		"Server": func(options struct{ Users string }) string { return "server" },
+		"Other":  func() string { return "other" },
+	}.AsLoader(WithLogger(logger), WithSlowThreshold(10*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := loader.Events(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		loader.MustLoad("Server")
+		close(done)
+	}()
+	for e := range events {
+		if e.Kind == LoadSlow {
+			if e.Component != "Database" || e.Duration < 10*time.Millisecond {
+				t.Error("unexpected event: ", e)
+			}
+			break
+		}
+	}
+	close(release)
+	<-done
+
+	logger.m.Lock()
+	defer logger.m.Unlock()
+	if len(logger.messages) == 0 {
+		t.Fatal("expected a warning")
+	}
+	message := logger.messages[0]
+	if !strings.Contains(message, "'Database' is still loading") ||
+		!strings.Contains(message, "blocking 'Server', 'Users'") {
+		t.Error("unexpected warning: ", message)
+	}
+}
@@ -0,0 +1,210 @@
+package acyclicloader
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// A Snapshotter is a component value that can be persisted by Snapshot() and
+// restored by RestoreSnapshot(). UnmarshalSnapshot is called on a new value of
+// the component type, or the value it points to if the type is a pointer.
+//
+// Values implementing encoding.BinaryMarshaler and encoding.BinaryUnmarshaler,
+// or json.Marshaler and json.Unmarshaler, can also be persisted.
+type Snapshotter interface {
+	MarshalSnapshot() ([]byte, error)
+	UnmarshalSnapshot(data []byte) error
+}
+
+var (
+	typeOfSnapshotter       = reflect.TypeOf((*Snapshotter)(nil)).Elem()
+	typeOfBinaryMarshaler   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	typeOfBinaryUnmarshaler = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	typeOfJSONMarshaler     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeOfJSONUnmarshaler   = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// A snapshot holds the persisted values of components, see Snapshot().
+type snapshot struct {
+	Components map[string]snapshotEntry `json:"components"`
+}
+
+type snapshotEntry struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"` // "snapshot", "binary" or "json"
+	Data     []byte `json:"data"`
+	Version  string `json:"version,omitempty"` // see Version()
+}
+
+// Snapshot returns the values of loaded components, that can be persisted and
+// given to RestoreSnapshot() after a process restart. This allows expensive
+// pure computations, such as parsed templates or compiled rules, to be warm
+// started.
+//
+// Only components whose type implements Snapshotter, encoding.BinaryMarshaler
+// and encoding.BinaryUnmarshaler, or json.Marshaler and json.Unmarshaler are
+// included. Failed components, overwritten values, transient, keyed and pooled
+// components, and components inherited from a parent loader are not included.
+func (a *AcyclicLoader) Snapshot() ([]byte, error) {
+	a.m.Lock()
+	values := map[string]interface{}{}
+	types := map[string]reflect.Type{}
+	versions := map[string]string{}
+	for name, c := range a.components {
+		a.expire(c)
+		if !c.loaded || c.err != nil || c.overwritten || c.inherited || c.transient ||
+			c.keyed || c.element != nil || snapshotEncoding(c.result) == "" {
+			continue
+		}
+		if v := reflect.ValueOf(c.value); !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+			continue
+		}
+		values[name] = c.value
+		types[name] = c.result
+		versions[name] = c.version
+	}
+	a.m.Unlock()
+
+	s := snapshot{Components: make(map[string]snapshotEntry, len(values))}
+	for name, value := range values {
+		entry := snapshotEntry{
+			Type:     types[name].String(),
+			Encoding: snapshotEncoding(types[name]),
+			Version:  versions[name],
+		}
+		var err error
+		switch entry.Encoding {
+		case "snapshot":
+			entry.Data, err = value.(Snapshotter).MarshalSnapshot()
+		case "binary":
+			entry.Data, err = value.(encoding.BinaryMarshaler).MarshalBinary()
+		case "json":
+			entry.Data, err = value.(json.Marshaler).MarshalJSON()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot component '%s': %w", name, err)
+		}
+		s.Components[name] = entry
+	}
+	return json.Marshal(s)
+}
+
+// RestoreSnapshot restores values from a snapshot returned by Snapshot(), the
+// restored components are cached as if they had been loaded, without loading
+// their dependencies.
+//
+// Components that are already loaded or being loaded are not restored, neither
+// are components whose version has changed since the snapshot, see Version().
+// Values of components that are no longer defined, have changed type, or can
+// no longer be restored, are skipped with a warning. An error is returned if
+// data isn't a snapshot, or a value fails to unmarshal.
+func (a *AcyclicLoader) RestoreSnapshot(data []byte) error {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+
+	a.m.Lock()
+	types := map[string]reflect.Type{}
+	versionFuncs := map[string]func() string{}
+	for _, name := range sortedSnapshotKeys(s.Components) {
+		entry := s.Components[name]
+		c, ok := a.components[name]
+		switch {
+		case !ok:
+			a.logger.Printf("warning: skipping snapshot of undefined component '%s'", name)
+		case c.result == nil || c.result.String() != entry.Type || snapshotEncoding(c.result) != entry.Encoding:
+			a.logger.Printf("warning: skipping snapshot of component '%s', as its type has changed", name)
+		case !c.loaded && !c.loading && !c.overwritten && !c.inherited && !c.transient && !c.keyed && c.element == nil:
+			types[name] = c.result
+			if c.versionFunc != nil {
+				versionFuncs[name] = c.versionFunc
+			}
+		}
+	}
+	a.m.Unlock()
+
+	values := make(map[string]reflect.Value, len(types))
+	for name, t := range types {
+		if version, ok := versionFuncs[name]; ok && version() != s.Components[name].Version {
+			continue // inputs have changed since the snapshot was taken
+		}
+		value, err := unmarshalSnapshot(t, s.Components[name])
+		if err != nil {
+			return fmt.Errorf("failed to restore component '%s' from snapshot: %w", name, err)
+		}
+		values[name] = value
+	}
+
+	a.m.Lock()
+	defer a.m.Unlock()
+	for name, value := range values {
+		c := a.components[name]
+		if c.loaded || c.loading {
+			continue // loaded while unmarshalling
+		}
+		c.value = value.Interface()
+		c.err = nil
+		c.loaded = true
+		c.loadedAt = time.Now()
+		c.duration = 0
+		c.version = s.Components[name].Version
+	}
+	return nil
+}
+
+// snapshotEncoding returns the encoding used to snapshot values of type t, or
+// "" if values of type t cannot be persisted.
+func snapshotEncoding(t reflect.Type) string {
+	if t == nil || t.Kind() == reflect.Interface {
+		return "" // values cannot be restored without a concrete type
+	}
+	target := t // type on which unmarshal methods are called
+	if t.Kind() != reflect.Ptr {
+		target = reflect.PtrTo(t)
+	}
+	switch {
+	case t.Implements(typeOfSnapshotter) && target.Implements(typeOfSnapshotter):
+		return "snapshot"
+	case t.Implements(typeOfBinaryMarshaler) && target.Implements(typeOfBinaryUnmarshaler):
+		return "binary"
+	case t.Implements(typeOfJSONMarshaler) && target.Implements(typeOfJSONUnmarshaler):
+		return "json"
+	}
+	return ""
+}
+
+// unmarshalSnapshot returns a new value of type t restored from entry.
+func unmarshalSnapshot(t reflect.Type, entry snapshotEntry) (reflect.Value, error) {
+	var target, value reflect.Value
+	if t.Kind() == reflect.Ptr {
+		target = reflect.New(t.Elem())
+		value = target
+	} else {
+		target = reflect.New(t)
+		value = target.Elem()
+	}
+	var err error
+	switch entry.Encoding {
+	case "snapshot":
+		err = target.Interface().(Snapshotter).UnmarshalSnapshot(entry.Data)
+	case "binary":
+		err = target.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(entry.Data)
+	case "json":
+		err = target.Interface().(json.Unmarshaler).UnmarshalJSON(entry.Data)
+	}
+	return value, err
+}
+
+func sortedSnapshotKeys(entries map[string]snapshotEntry) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
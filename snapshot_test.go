@@ -0,0 +1,74 @@
+package acyclicloader
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type compiledRules struct {
+	rules []string
+}
+
+func (r *compiledRules) MarshalSnapshot() ([]byte, error) {
+	return []byte(strings.Join(r.rules, ",")), nil
+}
+
+func (r *compiledRules) UnmarshalSnapshot(data []byte) error {
+	r.rules = strings.Split(string(data), ",")
+	return nil
+}
+
+func TestSnapshot(t *testing.T) {
+	var compiled int32
+	components := Components{
+		"Source": func() string { return "allow,deny" },
+		"Rules": func(options struct{ Source string }) *compiledRules {
+			atomic.AddInt32(&compiled, 1)
+			return &compiledRules{rules: strings.Split(options.Source, ",")}
+		},
+		"Started": func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) },
+		"Count":   func() int { return 42 },
+	}
+	loader := components.AsLoader()
+	loader.MustLoad("Rules")
+	loader.MustLoad("Started")
+	loader.MustLoad("Count")
+	data, err := loader.Snapshot()
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+
+	logger := &testLogger{}
+	restored := components.AsLoader(WithLogger(logger))
+	if err := restored.RestoreSnapshot(data); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	rules := restored.MustLoad("Rules").(*compiledRules)
+	if strings.Join(rules.rules, ",") != "allow,deny" {
+		t.Error("unexpected rules: ", rules.rules)
+	}
+	if compiled != 1 {
+		t.Error("expected 'Rules' to be restored rather than loaded again")
+	}
+	if restored.Loaded("Source") || restored.Loaded("Count") {
+		t.Error("expected only components that can be persisted to be restored")
+	}
+	if !restored.MustLoad("Started").(time.Time).Equal(loader.MustLoad("Started").(time.Time)) {
+		t.Error("expected 'Started' to be restored with JSON")
+	}
+
+	changed := Components{
+		"Rules": func() []string { return nil },
+	}.AsLoader(WithLogger(logger))
+	if err := changed.RestoreSnapshot(data); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if changed.Loaded("Rules") || len(logger.messages) == 0 {
+		t.Error("expected components that changed type to be skipped with a warning")
+	}
+	if err := changed.RestoreSnapshot([]byte("not a snapshot")); err == nil {
+		t.Error("expected an error for invalid data")
+	}
+}
@@ -0,0 +1,72 @@
+package acyclicloader
+
+// GraphStats holds statistics about the dependency graph of an AcyclicLoader,
+// see AcyclicLoader.Stats().
+type GraphStats struct {
+	Components int // number of components
+	Edges      int // number of distinct dependencies between components
+	MaxDepth   int // number of components in the longest chain of dependencies
+	MaxFanIn   int // largest number of components depending on a component
+	MaxFanOut  int // largest number of components a component depends on
+	// Number of components in each topological wave, the first wave holds
+	// components without dependencies, and each following wave holds
+	// components depending only on components in earlier waves
+	Waves []int
+}
+
+// Stats returns statistics about the dependency graph, such that the shape of
+// the graph can be tracked over time. All dependencies are counted, including
+// lazy dependencies, feature flags and ordering constraints, see
+// Dependencies().
+func (a *AcyclicLoader) Stats() GraphStats {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	stats := GraphStats{Components: len(a.components)}
+	fanIn := make(map[string]int, len(a.components))
+	for _, c := range a.components {
+		var seen []string
+		for _, dep := range c.dependencies {
+			if !stringContains(seen, dep) {
+				seen = append(seen, dep)
+				fanIn[dep]++
+			}
+		}
+		stats.Edges += len(seen)
+		if len(seen) > stats.MaxFanOut {
+			stats.MaxFanOut = len(seen)
+		}
+	}
+	for _, n := range fanIn {
+		if n > stats.MaxFanIn {
+			stats.MaxFanIn = n
+		}
+	}
+
+	// The wave of a component is one more than the latest wave of its
+	// dependencies, waves are numbered from 1
+	waves := make(map[string]int, len(a.components))
+	var wave func(name string) int
+	wave = func(name string) int {
+		if w, ok := waves[name]; ok {
+			return w
+		}
+		w := 1
+		for _, dep := range a.components[name].dependencies {
+			if d := wave(dep) + 1; d > w {
+				w = d
+			}
+		}
+		waves[name] = w
+		return w
+	}
+	for name := range a.components {
+		w := wave(name)
+		for len(stats.Waves) < w {
+			stats.Waves = append(stats.Waves, 0)
+		}
+		stats.Waves[w-1]++
+	}
+	stats.MaxDepth = len(stats.Waves)
+	return stats
+}
@@ -0,0 +1,36 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	loader := Components{
+		"Config":   func() string { return "config" },
+		"Logger":   func() int { return 1 },
+		"Database": func(options struct{ Config string }) bool { return true },
+		"Cache":    func(options struct{ Config string }) uint { return 1 },
+		"Server": func(options struct {
+			Database bool
+			Cache    uint
+			Logger   int
+			Config   string
+		}) float64 {
+			return 1
+		},
+	}.AsLoader()
+
+	stats := loader.Stats()
+	expected := GraphStats{
+		Components: 5,
+		Edges:      6,
+		MaxDepth:   3,
+		MaxFanIn:   3,
+		MaxFanOut:  4,
+		Waves:      []int{2, 2, 1},
+	}
+	if fmt.Sprint(stats) != fmt.Sprint(expected) {
+		t.Errorf("expected %+v, got: %+v", expected, stats)
+	}
+}
@@ -0,0 +1,69 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Strict returns a clone of a, in which loading any of the given components
+// fails with a ForbiddenComponentError, unless it has been overwritten using
+// WithOverwrites() or WithOverwriteFuncs(). Components depending on them fail
+// too, such that tests accidentally using real resources fail fast:
+//
+//   strict, err := loader.Strict("Database", "EmailClient")
+//   test, err := strict.WithOverwrites(map[string]interface{}{
+//       "Database": fakeDatabase,
+//   })
+//   test.Load("Server") // fails if "Server" depends on "EmailClient"
+//
+// Values already loaded for the given components, and for components
+// depending on them, are purged from the clone. A ComponentDefinitionError is
+// returned if any of the given components is undefined, as this is most likely
+// a typo.
+func (a *AcyclicLoader) Strict(names ...string) (*AcyclicLoader, error) {
+	a.m.Lock()
+	for _, name := range names {
+		if _, ok := a.components[name]; !ok {
+			available := sortedKeys(a.components)
+			a.m.Unlock()
+			return nil, &ComponentDefinitionError{
+				Component: name,
+				message: fmt.Sprintf(
+					"cannot forbid undefined component '%s'%s", name,
+					describeAlternatives(suggestNames(name, available), available),
+				),
+			}
+		}
+	}
+	a.m.Unlock()
+
+	a2 := a.Clone()
+
+	a2.m.Lock()
+	defer a2.m.Unlock()
+
+	forbidden := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		c := a2.components[name]
+		if !c.overwritten {
+			c.forbidden = true
+			forbidden[name] = nil
+		}
+	}
+
+	needsPurging := a2.needsPurging(forbidden)
+	for name, c := range a2.components {
+		if needsPurging(name) && (c.loaded || len(c.instances) > 0) {
+			a2.purged = append(a2.purged, name)
+		}
+		if needsPurging(name) {
+			c.value = nil
+			c.err = nil
+			c.loaded = false
+			c.loading = false
+			c.instances = nil
+		}
+	}
+	sort.Strings(a2.purged)
+	return a2, nil
+}
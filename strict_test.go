@@ -0,0 +1,63 @@
+package acyclicloader
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	loader := Components{
+		"Database": func() string { return "real database" },
+		"Email":    func() string { return "real email" },
+		"Server": func(options struct{ Database string }) string {
+			return "server using " + options.Database
+		},
+	}.AsLoader()
+	loader.MustLoad("Server")
+
+	strict, err := loader.Strict("Database", "Email")
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	_, err = strict.Load("Server")
+	var e *ForbiddenComponentError
+	if !errors.As(err, &e) || e.Component != "Database" {
+		t.Fatal("expected ForbiddenComponentError, got: ", err)
+	}
+
+	overwritten, err := strict.WithOverwrites(map[string]interface{}{"Database": "fake database"})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if v := overwritten.MustLoad("Server"); v != "server using fake database" {
+		t.Error("expected fake database to be used, got: ", v)
+	}
+	if _, err := overwritten.Load("Email"); err == nil {
+		t.Error("expected 'Email' to remain forbidden")
+	}
+
+	replaced, err := strict.WithOverwriteFuncs(map[string]interface{}{
+		"Email": func() string { return "fake email" },
+	})
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if v := replaced.MustLoad("Email"); v != "fake email" {
+		t.Error("expected fake email to be used, got: ", v)
+	}
+	if _, err := replaced.Load("Database"); err == nil {
+		t.Error("expected 'Database' to remain forbidden")
+	}
+
+	if v := loader.MustLoad("Server"); v != "server using real database" {
+		t.Error("expected original loader to be unaffected, got: ", v)
+	}
+}
+
+func TestStrictUndefined(t *testing.T) {
+	_, err := Components{"Database": func() int { return 0 }}.AsLoader().Strict("Databse")
+	t.Logf("got error as expected: '%s'", err)
+	if _, ok := err.(*ComponentDefinitionError); !ok {
+		t.Error("expected a ComponentDefinitionError")
+	}
+}
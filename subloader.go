@@ -0,0 +1,77 @@
+package acyclicloader
+
+// Only returns a new set of components with only the given components, and
+// the components they depend on transitively, c is not modified. This is useful
+// for CLI commands reusing the components of a server, without validating or
+// carrying components they never use.
+//
+// Groups only contain members that are otherwise included, and names of
+// undefined components are ignored.
+func (c Components) Only(names ...string) Components {
+	result := make(Components, len(names))
+	var include func(name string)
+	include = func(name string) {
+		if _, ok := result[name]; ok {
+			return
+		}
+		fn, ok := c[name]
+		if !ok {
+			return
+		}
+		result[name] = fn
+		for _, dep := range definedDependencies(fn) {
+			include(dep.component)
+		}
+	}
+	for _, name := range names {
+		include(name)
+	}
+	return result
+}
+
+// SubLoader returns an AcyclicLoader with only the given components, and the
+// components they depend on transitively. The cache is shared with a as far as
+// it is currently loaded, and names of undefined components are ignored.
+//
+// Unlike Components.Only(), this follows dependencies as resolved by a, so
+// dependencies wired by type and members of groups are included. Roots given to
+// WithRoots() for a are dropped, as every component of the sub-loader is
+// reachable from the given components, and loaders derived from it, such as by
+// WithOverwrites(), don't check roots either.
+func (a *AcyclicLoader) SubLoader(roots ...string) *AcyclicLoader {
+	a.m.Lock()
+	definitions := make(Components, len(roots))
+	var include func(name string)
+	include = func(name string) {
+		if _, ok := definitions[name]; ok {
+			return
+		}
+		definitions[name] = a.definitions[name]
+		for _, dep := range a.components[name].dependencies {
+			include(dep)
+		}
+	}
+	for _, root := range roots {
+		if _, ok := a.components[root]; ok {
+			include(root)
+		}
+	}
+	// Inherited components are not defined in this loader
+	for name, fn := range definitions {
+		if fn == nil {
+			delete(definitions, name)
+		}
+	}
+	// Roots of a may not be defined here, and the sub-loader is reachable from
+	// defined by construction, so roots are cleared rather than replaced
+	options := append(a.options[:len(a.options):len(a.options)], WithRoots())
+	a.m.Unlock()
+
+	a2, err := New(definitions, options...)
+	if err != nil {
+		// A subset closed under dependencies cannot break a valid graph
+		panic(err)
+	}
+	inheritCache(a, a2, func(string) bool { return true })
+	return a2
+}
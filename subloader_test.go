@@ -0,0 +1,58 @@
+package acyclicloader
+
+import "testing"
+
+var serverComponents = Components{
+	"Config": func() string { return "config" },
+	"Database": func(options struct{ Config string }) string {
+		return "database"
+	},
+	"Server": func(options struct{ Database string }) string {
+		return "server"
+	},
+	"Migrate": func(options struct {
+		Database string
+		Server   string `component:",optional"`
+	}) string {
+		return "migrated"
+	},
+}
+
+func TestComponentsOnly(t *testing.T) {
+	only := serverComponents.Only("Database", "Undefined")
+	if len(only) != 2 || only["Config"] == nil || only["Database"] == nil {
+		t.Error("expected 'Database' and 'Config', got: ", only.Names())
+	}
+	if len(serverComponents) != 4 {
+		t.Error("expected original components to be unmodified")
+	}
+}
+
+func TestSubLoader(t *testing.T) {
+	var wired string
+	loader := Components{
+		"Config": func() string { return "config" },
+		"Server": func(options struct{ Settings string }) string {
+			wired = options.Settings
+			return "server"
+		},
+		"Worker": func() int { return 0 },
+	}.AsLoader(WithAutoWiring(), WithRoots("Server", "Worker"), WithStrictMode())
+	loader.MustLoad("Config")
+
+	sub := loader.SubLoader("Server")
+	if _, err := sub.Load("Worker"); err == nil {
+		t.Error("expected 'Worker' not to be defined in sub-loader")
+	}
+	if !sub.Loaded("Config") {
+		t.Error("expected cache to be shared with the loader")
+	}
+	if sub.MustLoad("Server") != "server" || wired != "config" {
+		t.Error("expected auto-wired dependency to be included")
+	}
+
+	// Loaders derived from the sub-loader must not check the roots again
+	if _, err := sub.Without("Server"); err != nil {
+		t.Error("unexpected error: ", err)
+	}
+}
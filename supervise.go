@@ -0,0 +1,106 @@
+package acyclicloader
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// maxBackoff is the maximum number of intervals Supervise() waits between
+// attempts to restart a component, unless WithSupervisorBackoff() is used.
+const maxBackoff = 32
+
+// Supervise checks loaded components every interval, and calls Refresh() for
+// components that have failed, such that the component and its dependents are
+// loaded again. This blocks until ctx is done, and returns ctx.Err().
+//
+//   go loader.Supervise(ctx, 5*time.Second)
+//
+// A component is considered failed, if its value has one of the following
+// methods and it reports a failure:
+//   Failed() <-chan struct{} // failed when the channel is closed or receives
+//   HealthCheck(context.Context) error // failed when an error is returned
+//
+// If a component fails to load again, it is retried with exponential backoff,
+// see WithSupervisorBackoff(), and a warning is reported. Dependents holding a
+// lazy getter for the component obtain the new value the next time the getter
+// is called.
+func (a *AcyclicLoader) Supervise(ctx context.Context, interval time.Duration) error {
+	backoff := a.backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(2*interval, maxBackoff*interval)
+	}
+	failures := map[string]int{}
+	retryAt := map[string]time.Time{}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		names, values, errs := a.supervisedComponents()
+		for i, name := range names {
+			if failures[name] == 0 && (errs[i] != nil || !a.failed(ctx, values[i], interval)) {
+				continue
+			}
+			if time.Now().Before(retryAt[name]) {
+				continue
+			}
+			err := a.Refresh(name)
+			if _, loadErr := a.Load(name); loadErr != nil {
+				failures[name]++
+				retryAt[name] = time.Now().Add(backoff.Delay(failures[name]))
+				a.logger.Printf("warning: failed to restart '%s': %s", name, loadErr)
+				continue
+			}
+			if err != nil {
+				a.logger.Printf("warning: failed to close '%s' while restarting: %s", name, err)
+			}
+			delete(failures, name)
+			delete(retryAt, name)
+		}
+	}
+}
+
+// failed returns true, if value reports a failure, see Supervise().
+func (a *AcyclicLoader) failed(ctx context.Context, value interface{}, timeout time.Duration) bool {
+	return healthCheck(ctx, value, timeout) != nil
+}
+
+// healthCheck returns an error, if value reports a failure, see Supervise().
+func healthCheck(ctx context.Context, value interface{}, timeout time.Duration) error {
+	if f, ok := value.(interface{ Failed() <-chan struct{} }); ok {
+		select {
+		case <-f.Failed():
+			return errors.New("component reported failure")
+		default:
+		}
+	}
+	if h, ok := value.(interface {
+		HealthCheck(context.Context) error
+	}); ok {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return h.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// supervisedComponents returns the sorted names of loaded components that can
+// be refreshed, along with their value/err pairs.
+func (a *AcyclicLoader) supervisedComponents() (names []string, values []interface{}, errs []error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	for _, name := range sortedKeys(a.components) {
+		c := a.components[name]
+		if c.loaded && !c.keyed && !c.transient && !c.inherited && !c.overwritten {
+			names = append(names, name)
+			values = append(values, c.value)
+			errs = append(errs, c.err)
+		}
+	}
+	return names, values, errs
+}
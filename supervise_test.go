@@ -0,0 +1,95 @@
+package acyclicloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testConnection struct {
+	id     int
+	failed chan struct{}
+}
+
+func (c *testConnection) Failed() <-chan struct{} { return c.failed }
+
+type testHealthy struct {
+	healthy bool
+}
+
+func (h *testHealthy) HealthCheck(ctx context.Context) error {
+	if !h.healthy {
+		return errors.New("unhealthy")
+	}
+	return nil
+}
+
+func TestSupervise(t *testing.T) {
+	var m sync.Mutex
+	connections := 0
+	loader := Components{
+		"Connection": func() (*testConnection, error) {
+			m.Lock()
+			defer m.Unlock()
+			connections++
+			if connections == 2 {
+				return nil, errors.New("connection refused")
+			}
+			return &testConnection{id: connections, failed: make(chan struct{})}, nil
+		},
+		"Client": func(options struct {
+			Connection func() (*testConnection, error) `component:",lazy"`
+		}) func() (*testConnection, error) {
+			return options.Connection
+		},
+	}.AsLoader(WithLogger(&testLogger{}))
+
+	client := loader.MustLoad("Client").(func() (*testConnection, error))
+	first := loader.MustLoad("Connection")
+	close(first.(*testConnection).failed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loader.Supervise(ctx, time.Millisecond)
+
+	// Connection fails once while restarting, and is retried with backoff
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if c, err := loader.Load("Connection"); err == nil && c.(*testConnection).id == 3 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if c, err := loader.Load("Connection"); err != nil || c.(*testConnection).id != 3 {
+		t.Fatal("expected third connection to be loaded, got: ", c, err)
+	}
+	if c, err := client(); err != nil || c.id != 3 {
+		t.Error("expected lazy getter to return the third connection, got: ", c, err)
+	}
+}
+
+func TestSuperviseHealthCheck(t *testing.T) {
+	var m sync.Mutex
+	loads := 0
+	loader := Components{
+		"Service": func() *testHealthy {
+			m.Lock()
+			defer m.Unlock()
+			loads++
+			return &testHealthy{healthy: loads > 1}
+		},
+	}.AsLoader()
+	loader.MustLoad("Service")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go loader.Supervise(ctx, time.Millisecond)
+	for ctx.Err() == nil && !loader.MustLoad("Service").(*testHealthy).healthy {
+		time.Sleep(time.Millisecond)
+	}
+	if !loader.MustLoad("Service").(*testHealthy).healthy {
+		t.Error("expected unhealthy 'Service' to be restarted")
+	}
+}
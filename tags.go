@@ -0,0 +1,44 @@
+package acyclicloader
+
+import "context"
+
+// LoadTagged loads all components tagged with tag concurrently, see Tagged().
+// This is useful for loading critical components before accepting traffic.
+//
+// If one or more components fail to load, the error from the alphabetically
+// first of said components is returned.
+func (a *AcyclicLoader) LoadTagged(tag string) error {
+	names := a.tagged(tag)
+	for _, name := range names {
+		a.goLoad(context.Background(), name)
+	}
+	for _, name := range names {
+		if _, err := a.Load(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ShutdownTagged closes all loaded components tagged with tag, and all loaded
+// components depending on them, see Shutdown().
+func (a *AcyclicLoader) ShutdownTagged(tag string) error {
+	_, err := a.shutdown(func(name string) bool {
+		return stringContains(a.components[name].tags, tag)
+	})
+	return err
+}
+
+// tagged returns the sorted names of components tagged with tag.
+func (a *AcyclicLoader) tagged(tag string) []string {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	var names []string
+	for _, name := range sortedKeys(a.components) {
+		if stringContains(a.components[name].tags, tag) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
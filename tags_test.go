@@ -0,0 +1,88 @@
+package acyclicloader
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadTagged(t *testing.T) {
+	var closed []string
+	var m sync.Mutex
+	loaded := map[string]bool{}
+	closer := func(name string) interface{} {
+		return func() *testCloser {
+			m.Lock()
+			defer m.Unlock()
+			loaded[name] = true
+			return &testCloser{name: name, closed: &closed}
+		}
+	}
+	loader := Components{
+		"Database": Annotate(closer("Database"), Tagged("critical")),
+		"Cache":    Annotate(closer("Cache"), Tagged("critical", "background")),
+		"Mailer":   Annotate(closer("Mailer"), Tagged("background")),
+		"Server":   closer("Server"),
+	}.AsLoader(WithStrictMode())
+
+	if err := loader.LoadTagged("critical"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if !loaded["Database"] || !loaded["Cache"] || loaded["Mailer"] || loaded["Server"] {
+		t.Error("expected only critical components to be loaded, got: ", loaded)
+	}
+
+	loader.MustLoad("Mailer")
+	if err := loader.ShutdownTagged("background"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(closed) != 2 || closed[0] != "Mailer" || closed[1] != "Cache" {
+		t.Error("expected 'Mailer' and 'Cache' to be closed, got: ", closed)
+	}
+
+	loader = Components{
+		"Broken": Annotate(func() (int, error) {
+			return 0, errors.New("broken")
+		}, Tagged("critical")),
+	}.AsLoader()
+	err := loader.LoadTagged("critical")
+	t.Logf("got error as expected: '%s'", err)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestLoadTaggedLoadsOnce(t *testing.T) {
+	var m sync.Mutex
+	loads := map[string]int{}
+	count := func(name string) {
+		time.Sleep(time.Millisecond) // give other goroutines a chance to load it
+		m.Lock()
+		defer m.Unlock()
+		loads[name]++
+	}
+	loader := Components{
+		"Config": func() string {
+			count("Config")
+			return "config"
+		},
+		"Database": Annotate(func(options struct{ Config string }) string {
+			count("Database")
+			return "database"
+		}, Tagged("critical")),
+		"Cache": Annotate(func(options struct{ Config string }) string {
+			count("Cache")
+			return "cache"
+		}, Tagged("critical")),
+	}.AsLoader()
+
+	if err := loader.LoadTagged("critical"); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	m.Lock()
+	defer m.Unlock()
+	if loads["Config"] != 1 || loads["Database"] != 1 || loads["Cache"] != 1 {
+		t.Error("expected each component to be loaded once, got: ", loads)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build go1.14
+// +build go1.14
+
+package acyclicloader
+
+import "testing"
+
+// TestClone returns a clone of a with the given overwrites, see WithOverwrites(),
+// and registers a cleanup function with t, that shuts down components loaded
+// by the test. Components loaded before TestClone() was called are shared with
+// a and are not closed.
+//
+//   func TestServer(t *testing.T) {
+//       clone := loader.TestClone(t, map[string]interface{}{
+//           "Database": fakeDatabase,
+//       })
+//       server := clone.MustLoad("Server").(*Server)
+//       ...
+//   }
+//
+// The test fails, if overwrites contains undefined components, or if closing
+// any of the components loaded by the test fails.
+func (a *AcyclicLoader) TestClone(t testing.TB, overwrites map[string]interface{}) *AcyclicLoader {
+	t.Helper()
+	clone, err := a.WithOverwrites(overwrites)
+	if err != nil {
+		t.Fatal("acyclicloader: ", err)
+	}
+
+	clone.m.Lock()
+	shared := make(map[string]bool, len(clone.components))
+	for name, c := range clone.components {
+		shared[name] = c.loaded || len(c.instances) > 0
+	}
+	clone.m.Unlock()
+
+	t.Cleanup(func() {
+		_, err := clone.shutdown(func(name string) bool { return !shared[name] })
+		if err != nil {
+			t.Error("acyclicloader: ", err)
+		}
+	})
+	return clone
+}
@@ -0,0 +1,38 @@
+//go:build go1.14
+// +build go1.14
+
+package acyclicloader
+
+import "testing"
+
+func TestTestClone(t *testing.T) {
+	var closed []string
+	loader := Components{
+		"Config": func() *testCloser {
+			return &testCloser{name: "Config", closed: &closed}
+		},
+		"Database": func(options struct{ Config *testCloser }) *testCloser {
+			return &testCloser{name: "Database", closed: &closed}
+		},
+		"Server": func(options struct{ Database *testCloser }) *testCloser {
+			return &testCloser{name: "Server", closed: &closed}
+		},
+	}.AsLoader()
+	config := loader.MustLoad("Config")
+
+	fake := &testCloser{name: "FakeDatabase", closed: &closed}
+	t.Run("test", func(t *testing.T) {
+		clone := loader.TestClone(t, map[string]interface{}{"Database": fake})
+		if clone.MustLoad("Config") != config {
+			t.Error("expected 'Config' to be shared with the original loader")
+		}
+		clone.MustLoad("Server")
+	})
+
+	if len(closed) != 1 || closed[0] != "Server" {
+		t.Error("expected only 'Server' to be closed, got: ", closed)
+	}
+	if loader.MustLoad("Config") != config {
+		t.Error("expected original loader to be unaffected")
+	}
+}
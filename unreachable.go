@@ -0,0 +1,74 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unreachable returns a sorted list of components that are not reachable from
+// any of the given roots, following all dependencies including lazy ones. Such
+// components are never loaded, unless loaded directly by name. Components
+// inherited from a parent loader and undefined roots are ignored.
+//
+// See WithRoots() for reporting unreachable components when the loader is
+// created.
+func (a *AcyclicLoader) Unreachable(roots ...string) []string {
+	a.m.Lock()
+	defer a.m.Unlock()
+	return a.unreachable(roots)
+}
+
+// unreachable returns the components not reachable from roots, this must be
+// called with a.m locked, or while creating a.
+func (a *AcyclicLoader) unreachable(roots []string) []string {
+	reached := make(map[string]bool, len(a.components))
+	var visit func(name string)
+	visit = func(name string) {
+		if reached[name] {
+			return
+		}
+		reached[name] = true
+		for _, dep := range a.components[name].dependencies {
+			visit(dep)
+		}
+	}
+	for _, root := range roots {
+		if _, ok := a.components[root]; ok {
+			visit(root)
+		}
+	}
+
+	var unreachable []string
+	for _, name := range sortedKeys(a.components) {
+		c := a.components[name]
+		// Fields of a struct embedding Out are reached with the struct
+		if !reached[name] && !c.inherited && !(c.output != "" && reached[c.output]) {
+			unreachable = append(unreachable, name)
+		}
+	}
+	return unreachable
+}
+
+// checkRoots returns an error if roots are undefined, or if there are
+// components not reachable from roots, see WithRoots().
+func (a *AcyclicLoader) checkRoots() error {
+	for _, root := range a.roots {
+		if _, ok := a.components[root]; !ok {
+			return &ComponentDefinitionError{
+				Component: root,
+				message:   fmt.Sprintf("root '%s' given to WithRoots() is not defined", root),
+			}
+		}
+	}
+	unreachable := a.unreachable(a.roots)
+	if len(unreachable) == 0 {
+		return nil
+	}
+	return a.warn(&ComponentDefinitionError{
+		Component: unreachable[0],
+		message: fmt.Sprintf(
+			"components not reachable from '%s': '%s'",
+			strings.Join(a.roots, "', '"), strings.Join(unreachable, "', '"),
+		),
+	})
+}
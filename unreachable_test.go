@@ -0,0 +1,49 @@
+package acyclicloader
+
+import (
+	"strings"
+	"testing"
+)
+
+var unreachableComponents = Components{
+	"Config": func() int { return 0 },
+	"Cache":  func() int { return 0 },
+	"Server": func(options struct {
+		Config int
+		Cache  func() (int, error) `component:",lazy"`
+	}) int {
+		return 0
+	},
+	"Worker": func(options struct{ Config int }) int { return 0 },
+	"Legacy": func() int { return 0 },
+}
+
+func TestUnreachable(t *testing.T) {
+	loader := unreachableComponents.AsLoader()
+	unreachable := loader.Unreachable("Server")
+	if len(unreachable) != 2 || unreachable[0] != "Legacy" || unreachable[1] != "Worker" {
+		t.Error("expected 'Legacy' and 'Worker' to be unreachable, got: ", unreachable)
+	}
+	if unreachable := loader.Unreachable("Server", "Worker", "Legacy"); len(unreachable) != 0 {
+		t.Error("expected all components to be reachable, got: ", unreachable)
+	}
+}
+
+func TestWithRoots(t *testing.T) {
+	logger := &testLogger{}
+	if _, err := New(unreachableComponents, WithRoots("Server", "Worker"), WithLogger(logger)); err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	if len(logger.messages) != 1 || !strings.Contains(logger.messages[0], "'Legacy'") {
+		t.Error("expected warning about 'Legacy', got: ", logger.messages)
+	}
+
+	_, err := New(unreachableComponents, WithRoots("Server", "Worker"), WithStrictMode())
+	if err == nil || err.Error() != "components not reachable from 'Server', 'Worker': 'Legacy'" {
+		t.Error("expected error in strict mode, got: ", err)
+	}
+
+	if _, err := New(unreachableComponents, WithRoots("Servr")); err == nil {
+		t.Error("expected error for undefined root")
+	}
+}
@@ -1,5 +1,14 @@
 package acyclicloader
 
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 func stringContains(values []string, value string) bool {
 	for _, v := range values {
 		if v == value {
@@ -8,3 +17,198 @@ func stringContains(values []string, value string) bool {
 	}
 	return false
 }
+
+// parseComponentTag returns the name of the component a field depends on, this
+// is the field name unless overwritten by a `component:"Name,flag,..."` tag, and
+// the comma-separated flags from the tag.
+func parseComponentTag(field reflect.StructField) (name string, flags []string) {
+	parts := strings.Split(field.Tag.Get("component"), ",")
+	name = strings.TrimSpace(parts[0])
+	if name == "" {
+		name = field.Name
+	}
+	for _, flag := range parts[1:] {
+		flags = append(flags, strings.TrimSpace(flag))
+	}
+	return name, flags
+}
+
+// optionsStruct returns the struct type of an options parameter of type t,
+// which may be a struct or a pointer to a struct, or nil if t is neither.
+func optionsStruct(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// newOptions returns a new argument for an options parameter of type t, and the
+// struct value in which dependencies should be set.
+func newOptions(t reflect.Type) (arg, options reflect.Value) {
+	if t.Kind() == reflect.Ptr {
+		arg = reflect.New(t.Elem())
+		return arg, arg.Elem()
+	}
+	options = reflect.New(t).Elem()
+	return options, options
+}
+
+// valueOf returns value as a reflect.Value of type t, this is the zero value
+// of t, if value is nil.
+func valueOf(t reflect.Type, value interface{}) reflect.Value {
+	if value == nil {
+		return reflect.Zero(t)
+	}
+	return reflect.ValueOf(value)
+}
+
+// walkDependencyFields calls fn with each field in the options struct t that
+// declares a dependency, and the index sequence of the field. Fields of
+// embedded structs are flattened, unless the embedded field has a component
+// tag. Walking stops if fn returns false.
+func walkDependencyFields(t reflect.Type, index []int, fn func(field reflect.StructField, index []int) bool) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(index[:len(index):len(index)], i)
+		_, tagged := field.Tag.Lookup("component")
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !tagged {
+			if !walkDependencyFields(field.Type, fieldIndex, fn) {
+				return false
+			}
+			continue
+		}
+		if !fn(field, fieldIndex) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedStrings(values []string) []string {
+	result := append([]string(nil), values...)
+	sort.Strings(result)
+	return result
+}
+
+func sortedStringKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys(components map[string]*component) []string {
+	keys := make([]string, 0, len(components))
+	for key := range components {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// suggestNames returns the names closest to name by edit distance, ignoring
+// names that are too different to be a plausible typo.
+func suggestNames(name string, names []string) []string {
+	maxDistance := len(name) / 3
+	var suggestions []string
+	best := maxDistance + 1
+	for _, candidate := range names {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(candidate))
+		if d < best {
+			best = d
+			suggestions = []string{candidate}
+		} else if d == best {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(t)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// parseDefault returns the value of a `default:"..."` tag for a field of type
+// t. Strings, booleans, numbers and durations are parsed with strconv and
+// time.ParseDuration(), other types are decoded from JSON.
+func parseDefault(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	var err error
+	switch {
+	case t == typeOfDuration:
+		var d time.Duration
+		d, err = time.ParseDuration(s)
+		v.SetInt(int64(d))
+	case t.Kind() == reflect.String:
+		v.SetString(s)
+	case t.Kind() == reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		v.SetBool(b)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, 0, t.Bits())
+		v.SetInt(i)
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr:
+		var u uint64
+		u, err = strconv.ParseUint(s, 0, t.Bits())
+		v.SetUint(u)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		v.SetFloat(f)
+	default:
+		err = json.Unmarshal([]byte(s), v.Addr().Interface())
+	}
+	return v, err
+}
+
+// isNilResult returns true, if value is a nil pointer, interface, map, channel
+// or function of type t. Nil slices are usable, and are not considered nil.
+func isNilResult(t reflect.Type, value interface{}) bool {
+	if t == nil {
+		return false // components that only return an error
+	}
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
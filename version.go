@@ -0,0 +1,63 @@
+package acyclicloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+)
+
+// HashFiles returns a function for Version(), returning a hash of the contents
+// of the files at paths. Files that can't be read are hashed as missing, such
+// that creating or removing a file also changes the version.
+//
+//   "Rules": Annotate(LoadRules, Version(HashFiles("rules.yaml"))),
+func HashFiles(paths ...string) func() string {
+	return func() string {
+		h := sha256.New()
+		for _, path := range paths {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				h.Write([]byte{0}) // missing
+				continue
+			}
+			h.Write([]byte{1})
+			h.Write(data)
+		}
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// RefreshChanged calls Refresh() for each loaded component whose version has
+// changed since it was loaded, see Version(). This returns a sorted list of the
+// components refreshed, and the first error from refreshing them.
+//
+// This makes hot reloading safe, as only components whose inputs changed are
+// loaded again, along with components depending on them.
+func (a *AcyclicLoader) RefreshChanged() ([]string, error) {
+	a.m.Lock()
+	versions := map[string]string{}
+	versionFuncs := map[string]func() string{}
+	for name, c := range a.components {
+		a.expire(c)
+		if c.versionFunc != nil && c.loaded && !c.overwritten && !c.inherited {
+			versions[name] = c.version
+			versionFuncs[name] = c.versionFunc
+		}
+	}
+	a.m.Unlock()
+
+	var changed []string
+	for name, version := range versionFuncs {
+		if version() != versions[name] {
+			changed = append(changed, name)
+		}
+	}
+	changed = sortedStrings(changed)
+	var err error
+	for _, name := range changed {
+		if refreshErr := a.Refresh(name); err == nil {
+			err = refreshErr
+		}
+	}
+	return changed, err
+}
@@ -0,0 +1,77 @@
+package acyclicloader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRefreshChanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acyclicloader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rules.txt")
+	if err := ioutil.WriteFile(path, []byte("allow"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var loads int32
+	components := Components{
+		"Rules": Annotate(func() (*compiledRules, error) {
+			atomic.AddInt32(&loads, 1)
+			data, err := ioutil.ReadFile(path)
+			return &compiledRules{rules: strings.Split(string(data), ",")}, err
+		}, Version(HashFiles(path))),
+		"Policy": func(options struct{ Rules *compiledRules }) string {
+			return "policy: " + strings.Join(options.Rules.rules, ",")
+		},
+	}
+	loader := components.AsLoader()
+	loader.MustLoad("Policy")
+	data, err := loader.Snapshot()
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+
+	refreshed, err := loader.RefreshChanged()
+	if err != nil || len(refreshed) != 0 || loads != 1 {
+		t.Error("expected nothing to be refreshed, got: ", refreshed, err)
+	}
+	restored := components.AsLoader()
+	if err := restored.RestoreSnapshot(data); err != nil || !restored.Loaded("Rules") {
+		t.Error("expected 'Rules' to be restored, got: ", err)
+	}
+	refreshed, err = restored.RefreshChanged()
+	if err != nil || len(refreshed) != 0 || loads != 1 {
+		t.Error("expected restored 'Rules' not to be refreshed, got: ", refreshed, err)
+	}
+	resnapshot, err := restored.Snapshot()
+	if err != nil {
+		t.Fatal("unexpected error: ", err)
+	}
+	again := components.AsLoader()
+	if err := again.RestoreSnapshot(resnapshot); err != nil || !again.Loaded("Rules") {
+		t.Error("expected 'Rules' to be restored from a snapshot of a restored loader, got: ", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("deny"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err = loader.RefreshChanged()
+	if err != nil || len(refreshed) != 1 || refreshed[0] != "Rules" {
+		t.Error("expected 'Rules' to be refreshed, got: ", refreshed, err)
+	}
+	if value := loader.MustLoad("Policy"); value != "policy: deny" {
+		t.Error("expected dependents to be refreshed, got: ", value)
+	}
+
+	stale := components.AsLoader()
+	if err := stale.RestoreSnapshot(data); err != nil || stale.Loaded("Rules") {
+		t.Error("expected 'Rules' not to be restored after its version changed, got: ", err)
+	}
+}
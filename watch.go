@@ -0,0 +1,80 @@
+package acyclicloader
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watch polls the files declared with WatchFiles() every interval, and calls
+// Refresh() for loaded components when their files are modified. This blocks
+// until ctx is done, and returns ctx.Err().
+//
+//   go loader.Watch(ctx, time.Second)
+//
+// Files are polled rather than watched with OS notifications to avoid external
+// dependencies. Failures to refresh a component are reported as warnings, as
+// the component will be loaded again when its files are fixed.
+func (a *AcyclicLoader) Watch(ctx context.Context, interval time.Duration) error {
+	modified := a.watchedModTimes()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		current := a.watchedModTimes()
+		names, watched := a.watchingComponents()
+		for i, name := range names {
+			changed := false
+			for _, path := range watched[i] {
+				if !current[path].Equal(modified[path]) {
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+			if err := a.Refresh(name); err != nil {
+				a.logger.Printf("warning: failed to refresh '%s' after files changed: %s", name, err)
+			}
+		}
+		modified = current
+	}
+}
+
+// watchingComponents returns the sorted names of loaded components declaring
+// files with WatchFiles(), along with the files declared by each component.
+func (a *AcyclicLoader) watchingComponents() (names []string, watched [][]string) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	for _, name := range sortedKeys(a.components) {
+		if c := a.components[name]; len(c.watched) > 0 && c.loaded {
+			names = append(names, name)
+			watched = append(watched, c.watched)
+		}
+	}
+	return names, watched
+}
+
+// watchedModTimes returns the modification time of all files declared with
+// WatchFiles(), files that don't exist have zero modification time.
+func (a *AcyclicLoader) watchedModTimes() map[string]time.Time {
+	a.m.Lock()
+	var paths []string
+	for _, c := range a.components {
+		paths = append(paths, c.watched...)
+	}
+	a.m.Unlock()
+
+	modified := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modified[path] = info.ModTime()
+		}
+	}
+	return modified
+}
@@ -0,0 +1,57 @@
+package acyclicloader
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acyclicloader-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "template.txt")
+	if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := Components{
+		"Template": Annotate(func() (string, error) {
+			data, err := ioutil.ReadFile(path)
+			return string(data), err
+		}, WatchFiles(path)),
+	}.AsLoader()
+	if loader.MustLoad("Template").(string) != "hello" {
+		t.Fatal("expected 'hello'")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- loader.Watch(ctx, 5*time.Millisecond) }()
+
+	time.Sleep(20 * time.Millisecond)
+	modTime := time.Now().Add(time.Second)
+	if err := ioutil.WriteFile(path, []byte("hallo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for loader.MustLoad("Template").(string) != "hallo" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if loader.MustLoad("Template").(string) != "hallo" {
+		t.Error("expected 'Template' to be refreshed")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Error("expected context.Canceled, got: ", err)
+	}
+}
@@ -0,0 +1,203 @@
+package acyclicloader
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Without returns a new set of components without the given components, and
+// without any components that depend on them, unless the dependency is
+// optional, c is not modified. This is useful
+// for building a slimmed-down variant of an application, such as a CLI tool
+// that doesn't need the HTTP server.
+//
+// Only dependencies declared by fields of options structs, feature flags and
+// ordering constraints are followed, see AcyclicLoader.Without() for removing
+// components wired by type.
+func (c Components) Without(names ...string) Components {
+	dependents := make(map[string][]string, len(c))
+	for name, fn := range c {
+		for _, dep := range definedDependencies(fn) {
+			if !dep.optional {
+				dependents[dep.component] = append(dependents[dep.component], name)
+			}
+		}
+	}
+
+	removed := make(map[string]bool, len(names))
+	var remove func(name string)
+	remove = func(name string) {
+		if removed[name] {
+			return
+		}
+		removed[name] = true
+		for _, dependent := range dependents[name] {
+			remove(dependent)
+		}
+	}
+	for _, name := range names {
+		remove(name)
+	}
+
+	result := make(Components, len(c))
+	for name, fn := range c {
+		if !removed[name] {
+			result[name] = fn
+		}
+	}
+	return result
+}
+
+// Without returns an AcyclicLoader without the given components, and without
+// any components that depend on them, unless the dependency is optional. The
+// cache is shared with a as far as it is currently loaded.
+//
+// Unlike Components.Without(), this follows dependencies as resolved by a, so
+// components depending on removed components by type, by plain parameters or
+// by keyed getters are removed too. Removing a component provided by a field
+// of a result embedding Out, removes the component providing it. Names of
+// undefined components are ignored, and an error is returned, if a component
+// is inherited from a parent loader.
+func (a *AcyclicLoader) Without(names ...string) (*AcyclicLoader, error) {
+	a.m.Lock()
+	dependents := make(map[string][]string, len(a.components))
+	for _, name := range sortedKeys(a.components) {
+		c := a.components[name]
+		for i, dep := range c.dependencies {
+			if !c.optional[i] {
+				dependents[dep] = append(dependents[dep], name)
+			}
+		}
+	}
+
+	var err error
+	removed := make(map[string]bool, len(names))
+	var remove func(name string)
+	remove = func(name string) {
+		c, ok := a.components[name]
+		if !ok || removed[name] || err != nil {
+			return
+		}
+		if c.inherited {
+			err = &ComponentDefinitionError{
+				Component: name,
+				message: fmt.Sprintf(
+					"cannot remove '%s', as it is inherited from the parent loader", name,
+				),
+			}
+			return
+		}
+		removed[name] = true
+		if c.output != "" {
+			remove(c.output) // outputs are defined by the component providing them
+		}
+		for _, dependent := range dependents[name] {
+			remove(dependent)
+		}
+	}
+	for _, name := range names {
+		remove(name)
+	}
+
+	definitions := make(Components, len(a.definitions))
+	for name, fn := range a.definitions {
+		if !removed[name] {
+			definitions[name] = fn
+		}
+	}
+	options := a.options
+	a.m.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	a2, err := New(definitions, options...)
+	if err != nil {
+		return nil, err
+	}
+	inheritCache(a, a2, func(string) bool { return true })
+	return a2, nil
+}
+
+// A definedDependency is a dependency found in the definition of a component.
+type definedDependency struct {
+	field     string // name of the field in the options struct, if any
+	component string // name of the component depended on, unless wired by type
+	optional  bool
+	byType    reflect.Type // type of the component depended on, if wired by type
+}
+
+// definedDependencies returns the dependencies declared by the options struct
+// in the definition of a component, or by any of its definitions for different
+// profiles. This returns nil, if the definition isn't a function taking a
+// struct, and doesn't have a feature flag or ordering constraints.
+func definedDependencies(fn interface{}) []definedDependency {
+	if p, ok := fn.(*profiled); ok {
+		var dependencies []definedDependency
+		for _, variant := range p.variants() {
+			dependencies = append(dependencies, definedDependencies(variant)...)
+		}
+		return dependencies
+	}
+	probe := &component{definition: &definition{}}
+	if an, ok := fn.(*Annotated); ok {
+		fn = an.fn
+		for _, annotate := range an.annotations {
+			annotate(probe)
+		}
+	}
+	var dependencies []definedDependency
+	if probe.enabledBy != "" {
+		dependencies = append(dependencies, definedDependency{component: probe.enabledBy})
+	}
+	for _, dep := range probe.after {
+		dependencies = append(dependencies, definedDependency{component: dep})
+	}
+	t := reflect.TypeOf(fn)
+	skip := 0
+	if probe.keyed {
+		skip = 1 // the key isn't a dependency
+	}
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() <= skip {
+		return dependencies
+	}
+	if (probe.paramsByType && !t.IsVariadic()) || hasPlainParams(t, skip) {
+		// Plain parameters are wired by type through fields named by paramName()
+		for i := skip; i < t.NumIn(); i++ {
+			dependencies = append(dependencies, definedDependency{
+				field:  paramName(i - skip),
+				byType: t.In(i),
+			})
+		}
+		return dependencies
+	}
+	if t.NumIn() != skip+1 || optionsStruct(t.In(skip)) == nil {
+		return dependencies
+	}
+	walkDependencyFields(optionsStruct(t.In(skip)), nil, func(field reflect.StructField, _ []int) bool {
+		if field.Type == typeOfInfo || field.Type == typeOfContext {
+			return true
+		}
+		name, flags := parseComponentTag(field)
+		if stringContains(flags, "group") {
+			return true // groups depend on whichever components are defined
+		}
+		dep := definedDependency{
+			field:     field.Name,
+			component: name,
+			optional:  stringContains(flags, "optional"),
+		}
+		if renamed, ok := probe.renamed[field.Name]; ok {
+			dep.component = renamed
+		} else if stringContains(flags, "bytype") {
+			dep.component = ""
+			dep.byType = field.Type
+			if stringContains(flags, "lazy") && field.Type.Kind() == reflect.Func && field.Type.NumOut() > 0 {
+				dep.byType = field.Type.Out(0)
+			}
+		}
+		dependencies = append(dependencies, dep)
+		return true
+	})
+	return dependencies
+}
@@ -0,0 +1,78 @@
+package acyclicloader
+
+import "testing"
+
+func TestWithout(t *testing.T) {
+	components := Components{
+		"Config":   func() int { return 5 },
+		"Database": func(options struct{ Config int }) int { return options.Config },
+		"Handler":  func(options struct{ Database int }) int { return options.Database },
+		"Server":   func(options struct{ Handler, Config int }) int { return options.Handler },
+		"CLI":      func(options struct{ Config int }) int { return options.Config + 1 },
+	}
+
+	slim := components.Without("Handler")
+	if len(slim) != 3 || slim["Server"] != nil || slim["Handler"] != nil {
+		t.Error("expected 'Handler' and 'Server' to be removed")
+	}
+	if len(components) != 5 {
+		t.Error("expected Without not to modify the components")
+	}
+
+	loader := components.AsLoader()
+	if loader.MustLoad("Database").(int) != 5 {
+		t.Error("expected 5")
+	}
+	cli, err := loader.Without("Database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cli.MustLoad("CLI").(int) != 6 {
+		t.Error("expected 6")
+	}
+	if _, err := cli.Load("Server"); err == nil {
+		t.Error("expected 'Server' to be removed")
+	}
+}
+
+func TestWithoutResolvedDependencies(t *testing.T) {
+	type Config map[string]int
+	type Database struct{ Config Config }
+	loader := Components{
+		"Config":   func() Config { return Config{"port": 80} },
+		"Database": func(config Config) *Database { return &Database{Config: config} },
+		"Users": func(options struct {
+			DB *Database `component:",bytype"`
+		}) int {
+			return options.DB.Config["port"]
+		},
+		"Port": func(options struct {
+			Config Config `component:",optional"`
+		}) int {
+			return options.Config["port"]
+		},
+	}.AsLoader()
+
+	slim, err := loader.Without("Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Config", "Database", "Users"} {
+		if _, err := slim.Load(name); err == nil {
+			t.Errorf("expected '%s' to be removed", name)
+		}
+	}
+	if slim.MustLoad("Port").(int) != 0 {
+		t.Error("expected optional dependency to be left as zero value")
+	}
+
+	child, err := loader.Child(Components{
+		"Handler": func(options struct{ Port int }) int { return options.Port },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := child.Without("Port"); err == nil {
+		t.Error("expected error removing an inherited component")
+	}
+}